@@ -63,7 +63,7 @@ var baseDirName = path.Join("prisma", "binaries")
 // internally, this is the global temp dir
 func GlobalTempDir(version string) string {
 	temp := os.TempDir()
-	logger.Debug.Printf("temp dir: %s", temp)
+	logger.Default().Debug("temp dir", "path", temp)
 
 	return path.Join(temp, baseDirName, "engines", version)
 }
@@ -80,32 +80,31 @@ func GlobalCacheDir() string {
 		panic(fmt.Errorf("could not read user cache dir: %w", err))
 	}
 
-	logger.Debug.Printf("global cache dir: %s", cache)
+	logger.Default().Debug("global cache dir", "path", cache)
 
 	return path.Join(cache, baseDirName, "cli", PrismaVersion)
 }
 
 func FetchEngine(dir string, engineName string, binaryName string) error {
-	logger.Debug.Printf("checking %s %s...", engineName, binaryName)
+	log := logger.Default().With("engine", engineName)
+	log.Debug("checking engine...", "binary", binaryName)
 
 	to := GetEnginePath(dir, engineName, binaryName)
 
 	if _, err := os.Stat(to); !os.IsNotExist(err) {
-		logger.Debug.Printf("%s is cached at %s", engineName, to)
+		log.Debug("engine is cached", "path", to)
 		return nil
 	}
 
 	url := platform.CheckForExtension(binaryName, fmt.Sprintf(EngineURL, EngineVersion, binaryName, engineName))
 
-	logger.Debug.Printf("%s is missing, downloading...", engineName)
-
-	logger.Debug.Printf("downloading %s from %s to %s", engineName, url, to)
+	log.Debug("engine is missing, downloading...", "url", url, "path", to)
 
 	if err := download(url, to); err != nil {
 		return fmt.Errorf("could not download %s to %s: %w", url, to, err)
 	}
 
-	logger.Debug.Printf("%s done", engineName)
+	log.Debug("engine downloaded")
 
 	return nil
 }
@@ -138,18 +137,19 @@ func DownloadCLI(toDir string) error {
 	to := platform.CheckForExtension(platform.Name(), path.Join(toDir, cli))
 	url := platform.CheckForExtension(platform.Name(), fmt.Sprintf(PrismaURL, "prisma-cli", PrismaVersion, platform.Name(), platform.Arch()))
 
-	logger.Debug.Printf("ensuring CLI %s from %s to %s", cli, url, to)
+	log := logger.Default()
+	log.Debug("ensuring CLI", "cli", cli, "url", url, "path", to)
 
 	if _, err := os.Stat(to); os.IsNotExist(err) {
-		logger.Info.Printf("prisma cli doesn't exist, fetching... (this might take a few minutes)")
+		log.Info("prisma cli doesn't exist, fetching... (this might take a few minutes)")
 
 		if err := download(url, to); err != nil {
 			return fmt.Errorf("could not download %s to %s: %w", url, to, err)
 		}
 
-		logger.Info.Printf("prisma cli fetched successfully.")
+		log.Info("prisma cli fetched successfully")
 	} else {
-		logger.Debug.Printf("prisma cli is cached")
+		log.Debug("prisma cli is cached")
 	}
 
 	return nil
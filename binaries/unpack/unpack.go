@@ -36,7 +36,7 @@ func Unpack(data []byte, name string, version string) {
 	}
 
 	if _, err := os.Stat(file); err == nil {
-		logger.Debug.Printf("query engine exists, not unpacking. %s. at %s", time.Since(start), file)
+		logger.Default().Debug("query engine exists, not unpacking", "path", file, "duration", time.Since(start))
 		return
 	}
 
@@ -57,7 +57,7 @@ func Unpack(data []byte, name string, version string) {
 		panic(fmt.Errorf("could not chmod +x %s: %w", file, err))
 	}
 
-	logger.Debug.Printf("unpacked at %s in %s", file, time.Since(start))
+	logger.Default().Debug("unpacked query engine", "path", file, "duration", time.Since(start))
 
 	if err := os.Setenv(FileEnv, file); err != nil {
 		panic(err)
@@ -13,7 +13,8 @@ import (
 
 // Run the prisma CLI with given arguments
 func Run(arguments []string, output bool) error {
-	logger.Debug.Printf("running cli with args %+v", arguments)
+	log := logger.Default()
+	log.Debug("running cli", "args", arguments)
 	// TODO respect initial PRISMA_<name>_BINARY env
 	// TODO optionally override CLI filepath using PRISMA_CLI_PATH
 
@@ -25,7 +26,7 @@ func Run(arguments []string, output bool) error {
 
 	prisma := binaries.PrismaCLIName()
 
-	logger.Debug.Printf("running %s %+v", path.Join(dir, prisma), arguments)
+	log.Debug("running prisma cli", "path", path.Join(dir, prisma), "args", arguments)
 
 	cmd := exec.Command(path.Join(dir, prisma), arguments...) //nolint:gosec
 	binaryName := platform.CheckForExtension(platform.Name(), platform.BinaryPlatformNameStatic())
@@ -38,7 +39,7 @@ func Run(arguments []string, output bool) error {
 		var value string
 
 		if env := os.Getenv(engine.Env); env != "" {
-			logger.Debug.Printf("overriding %s to %s", engine.Name, env)
+			log.Debug("overriding engine", "engine", engine.Name, "path", env)
 			value = env
 		} else {
 			value = path.Join(dir, binaries.EngineVersion, fmt.Sprintf("prisma-%s-%s", engine.Name, binaryName))
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// LogLevelFlag is the flag which sets the log level of Prisma Client Go, e.g. `--log-level debug`
+const LogLevelFlag = "--log-level"
+
+// ParseLogLevel removes the log level flag from the arguments, as they are passed on to the prisma CLI.
+// ok is false if the flag is not set.
+func ParseLogLevel(arguments []string) (rest []string, level slog.Level, ok bool, err error) {
+	rest = make([]string, 0, len(arguments))
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+
+		var value string
+		switch {
+		case arg == LogLevelFlag:
+			if i+1 >= len(arguments) {
+				return nil, 0, false, fmt.Errorf("%s requires a value", LogLevelFlag)
+			}
+			i++
+			value = arguments[i]
+		case strings.HasPrefix(arg, LogLevelFlag+"="):
+			value = strings.TrimPrefix(arg, LogLevelFlag+"=")
+		default:
+			rest = append(rest, arg)
+			continue
+		}
+
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			return nil, 0, false, fmt.Errorf("invalid %s %q: use debug, info, warn or error", LogLevelFlag, value)
+		}
+		ok = true
+	}
+	return rest, level, ok, nil
+}
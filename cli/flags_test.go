@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"log/slog"
+	"reflect"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		rest    []string
+		level   slog.Level
+		ok      bool
+		wantErr bool
+	}{{
+		name: "no flag",
+		args: []string{"generate", "--schema", "schema.prisma"},
+		rest: []string{"generate", "--schema", "schema.prisma"},
+	}, {
+		name:  "separate value",
+		args:  []string{"--log-level", "debug", "generate"},
+		rest:  []string{"generate"},
+		level: slog.LevelDebug,
+		ok:    true,
+	}, {
+		name:  "inline value",
+		args:  []string{"db", "push", "--log-level=WARN"},
+		rest:  []string{"db", "push"},
+		level: slog.LevelWarn,
+		ok:    true,
+	}, {
+		name:    "missing value",
+		args:    []string{"generate", "--log-level"},
+		wantErr: true,
+	}, {
+		name:    "invalid value",
+		args:    []string{"--log-level=verbose", "generate"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest, level, ok, err := ParseLogLevel(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLogLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(rest, tt.rest) || level != tt.level || ok != tt.ok {
+				t.Errorf("ParseLogLevel() = %v, %s, %t, want %v, %s, %t", rest, level, ok, tt.rest, tt.level, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package dbtest provisions an isolated database for each test, pushes the Prisma schema to it and returns a
+// connected client, so tests of code using a generated client don't interfere with each other.
+//
+// For SQLite schemas, the database is a file in a temporary directory of the test. For PostgreSQL schemas, a
+// database schema with a random name is created in the database given via WithDatabaseURL or the
+// PRISMA_CLIENT_GO_TEST_DATABASE_URL env var. Everything is removed when the test is done.
+//
+// Example:
+//
+//	func newClient(t *testing.T) *db.PrismaClient {
+//		return dbtest.New(t, "../schema.prisma", func(url string) dbtest.Client {
+//			return db.NewClient(db.WithDatasourceURL(url))
+//		}).(*db.PrismaClient)
+//	}
+package dbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vnsoft2014/prisma-client-go/cli"
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+	"github.com/vnsoft2014/prisma-client-go/runtime/transaction"
+)
+
+// Env is the env var which contains the url of the PostgreSQL database used if WithDatabaseURL is not set
+const Env = "PRISMA_CLIENT_GO_TEST_DATABASE_URL"
+
+// rollbackTimeout is the maximum duration of a test with WithRollback, after which the query engine
+// rolls back the transaction; it matches the default timeout of go test
+const rollbackTimeout = 10 * time.Minute
+
+// Client is implemented by the PrismaClient of every generated client
+type Client interface {
+	engine.Engine
+	engine.Transactional
+	Use(middleware ...builder.Middleware)
+}
+
+// Option configures how the database of a test is set up
+type Option func(*options)
+
+type options struct {
+	url      string
+	rollback bool
+}
+
+// WithDatabaseURL sets the url of the PostgreSQL database in which a schema is created for each test.
+// It takes precedence over the PRISMA_CLIENT_GO_TEST_DATABASE_URL env var, and is ignored for SQLite.
+func WithDatabaseURL(url string) Option {
+	return func(o *options) {
+		o.url = url
+	}
+}
+
+// WithRollback starts an interactive transaction which is rolled back when the test is done, and sends all queries,
+// raw queries and batch transactions (Prisma.Transaction) of the client within it, so they are never committed.
+// Interactive transactions started via Prisma.Tx are not part of it: their queries run in their own transaction,
+// which doesn't see the uncommitted writes of the test and is committed as usual. For SQLite, they wait for the test
+// transaction to finish, so use Prisma.Tx only without WithRollback.
+func WithRollback() Option {
+	return func(o *options) {
+		o.rollback = true
+	}
+}
+
+// push creates the tables of a schema; it's a variable so tests can run without the Prisma CLI
+var push = func(schemaPath string) error {
+	return cli.Run([]string{"db", "push", "--schema=" + schemaPath, "--skip-generate", "--accept-data-loss"}, testing.Verbose())
+}
+
+// execute runs a SQL script on a database
+var execute = func(url string, script string) error {
+	f, err := os.CreateTemp("", "dbtest-*.sql")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(script); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return cli.Run([]string{"db", "execute", "--url", url, "--file", f.Name()}, testing.Verbose())
+}
+
+// New provisions an isolated database for the test, pushes the schema at schemaPath to it, and returns a client
+// created by newClient with the url of the database and connected. The client is disconnected and the database
+// is removed via t.Cleanup.
+func New(t testing.TB, schemaPath string, newClient func(url string) Client, opts ...Option) Client {
+	t.Helper()
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	src, err := os.ReadFile(schemaPath)
+	if err != nil {
+		t.Fatalf("could not read schema: %s", err)
+	}
+	provider, err := datasourceProvider(string(src))
+	if err != nil {
+		t.Fatalf("%s: %s", schemaPath, err)
+	}
+
+	var url string
+	switch provider {
+	case "sqlite":
+		url = "file:" + filepath.Join(t.TempDir(), "test.db")
+	case "postgresql", "postgres":
+		base := o.url
+		if base == "" {
+			base = os.Getenv(Env)
+		}
+		if base == "" {
+			t.Fatalf("no PostgreSQL database url; set %s or use dbtest.WithDatabaseURL", Env)
+		}
+		name := "test_" + randomHex(8)
+		if url, err = withSchema(base, name); err != nil {
+			t.Fatalf("invalid database url: %s", err)
+		}
+		t.Cleanup(func() {
+			if err := execute(base, fmt.Sprintf(`DROP SCHEMA IF EXISTS "%s" CASCADE;`, name)); err != nil {
+				t.Errorf("could not drop schema %s: %s", name, err)
+			}
+		})
+	default:
+		t.Fatalf("%s: dbtest does not support the %s provider", schemaPath, provider)
+	}
+
+	pushed := filepath.Join(t.TempDir(), "schema.prisma")
+	if err := os.WriteFile(pushed, []byte(replaceURL(string(src), url)), 0644); err != nil {
+		t.Fatalf("could not write schema: %s", err)
+	}
+	if err := push(pushed); err != nil {
+		t.Fatalf("could not push schema: %s", err)
+	}
+
+	client := newClient(url)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("could not connect: %s", err)
+	}
+	t.Cleanup(func() {
+		if err := client.Disconnect(); err != nil {
+			t.Errorf("could not disconnect: %s", err)
+		}
+	})
+
+	if o.rollback {
+		rollback(t, client)
+	}
+
+	return client
+}
+
+// rollback starts a transaction which is rolled back when the test is done, and sends all requests of the client
+// within it, including batches. Queries of interactive transactions keep their own transaction id, as it is set
+// closer to the engine.
+func rollback(t testing.TB, client Client) {
+	t.Helper()
+
+	id, err := client.StartTx(context.Background(), engine.TxOptions{
+		MaxWait: transaction.DefaultMaxWait,
+		Timeout: rollbackTimeout,
+	})
+	if err != nil {
+		t.Fatalf("could not start transaction: %s", err)
+	}
+
+	client.Use(func(next builder.Handler) builder.Handler {
+		return func(ctx context.Context, req *builder.Request) error {
+			// read replicas don't know the transaction
+			if req.Query != nil {
+				req.Query.UsePrimary = true
+			}
+			return next(engine.WithTxID(ctx, id), req)
+		}
+	})
+
+	t.Cleanup(func() {
+		if err := client.RollbackTx(context.Background(), id); err != nil {
+			t.Errorf("could not roll back transaction: %s", err)
+		}
+	})
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,212 @@
+package dbtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+)
+
+type fakeClient struct {
+	url        string
+	connected  bool
+	middleware []builder.Middleware
+	rolledBack string
+}
+
+func (c *fakeClient) Name() string { return "fake" }
+
+func (c *fakeClient) Connect() error {
+	c.connected = true
+	return nil
+}
+
+func (c *fakeClient) Disconnect() error {
+	c.connected = false
+	return nil
+}
+
+func (c *fakeClient) Do(ctx context.Context, payload interface{}, v interface{}) error { return nil }
+
+func (c *fakeClient) Batch(ctx context.Context, payload interface{}, v interface{}) error { return nil }
+
+func (c *fakeClient) StartTx(ctx context.Context, opts engine.TxOptions) (string, error) {
+	return "tx1", nil
+}
+
+func (c *fakeClient) CommitTx(ctx context.Context, id string) error { return nil }
+
+func (c *fakeClient) RollbackTx(ctx context.Context, id string) error {
+	c.rolledBack = id
+	return nil
+}
+
+func (c *fakeClient) Use(middleware ...builder.Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+const testSchema = `datasource db {
+  provider = "%s" // the provider
+  url      = env("DATABASE_URL")
+}
+
+model User {
+  id  String @id
+  url String
+}
+`
+
+// stub replaces the Prisma CLI calls and returns the pushed schemas and executed scripts
+func stub(t *testing.T) (*[]string, *[]string) {
+	var pushed, executed []string
+	originalPush, originalExecute := push, execute
+	t.Cleanup(func() {
+		push, execute = originalPush, originalExecute
+	})
+	push = func(schemaPath string) error {
+		b, err := os.ReadFile(schemaPath)
+		pushed = append(pushed, string(b))
+		return err
+	}
+	execute = func(url string, script string) error {
+		executed = append(executed, url+": "+script)
+		return nil
+	}
+	return &pushed, &executed
+}
+
+func writeSchema(t *testing.T, provider string) string {
+	path := filepath.Join(t.TempDir(), "schema.prisma")
+	if err := os.WriteFile(path, []byte(strings.Replace(testSchema, "%s", provider, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNew_SQLite(t *testing.T) {
+	pushed, _ := stub(t)
+	schemaPath := writeSchema(t, "sqlite")
+
+	var client *fakeClient
+	t.Run("test", func(t *testing.T) {
+		New(t, schemaPath, func(url string) Client {
+			client = &fakeClient{url: url}
+			return client
+		})
+
+		if !client.connected {
+			t.Errorf("expected the client to be connected")
+		}
+		if !strings.HasPrefix(client.url, "file:") || !strings.HasSuffix(client.url, "test.db") {
+			t.Errorf("unexpected url %s", client.url)
+		}
+		if len(*pushed) != 1 || !strings.Contains((*pushed)[0], `  url = "`+client.url+`"`) {
+			t.Errorf("unexpected pushed schema %v", *pushed)
+		}
+	})
+
+	if client.connected {
+		t.Errorf("expected the client to be disconnected after the test")
+	}
+}
+
+func TestNew_PostgreSQL(t *testing.T) {
+	_, executed := stub(t)
+	schemaPath := writeSchema(t, "postgresql")
+
+	var client *fakeClient
+	t.Run("test", func(t *testing.T) {
+		New(t, schemaPath, func(url string) Client {
+			client = &fakeClient{url: url}
+			return client
+		}, WithDatabaseURL("postgresql://user:pw@localhost:5432/db?sslmode=disable"))
+
+		if !strings.HasPrefix(client.url, "postgresql://user:pw@localhost:5432/db?schema=test_") ||
+			!strings.HasSuffix(client.url, "&sslmode=disable") {
+			t.Errorf("unexpected url %s", client.url)
+		}
+	})
+
+	schema := client.url[strings.Index(client.url, "test_"):strings.Index(client.url, "&")]
+	expected := `postgresql://user:pw@localhost:5432/db?sslmode=disable: DROP SCHEMA IF EXISTS "` + schema + `" CASCADE;`
+	if len(*executed) != 1 || (*executed)[0] != expected {
+		t.Errorf("expected the schema to be dropped, got %v", *executed)
+	}
+}
+
+func TestNew_Rollback(t *testing.T) {
+	stub(t)
+	schemaPath := writeSchema(t, "sqlite")
+
+	var client *fakeClient
+	t.Run("test", func(t *testing.T) {
+		New(t, schemaPath, func(url string) Client {
+			client = &fakeClient{url: url}
+			return client
+		}, WithRollback())
+
+		if len(client.middleware) != 1 {
+			t.Fatalf("expected a middleware, got %d", len(client.middleware))
+		}
+		req := &builder.Request{Query: &builder.Query{}}
+		err := client.middleware[0](func(ctx context.Context, req *builder.Request) error {
+			if id := engine.TxID(ctx); id != "tx1" {
+				t.Errorf("expected the query to be sent within the transaction, got %q", id)
+			}
+			return nil
+		})(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !req.Query.UsePrimary {
+			t.Errorf("expected the query to be sent to the primary")
+		}
+		if client.rolledBack != "" {
+			t.Errorf("rolled back before the test is done")
+		}
+	})
+
+	if client.rolledBack != "tx1" {
+		t.Errorf("expected the transaction to be rolled back, got %q", client.rolledBack)
+	}
+}
+
+func TestReplaceURL(t *testing.T) {
+	schema := `datasource db {
+  provider  = "postgresql"
+  url       = env("DATABASE_URL")
+  directUrl = env("DIRECT_URL")
+}
+
+model User {
+  id  String @id
+  url String
+}
+`
+	expected := `datasource db {
+  provider  = "postgresql"
+  url = "postgresql://localhost/db?schema=\"a\""
+  directUrl = "postgresql://localhost/db?schema=\"a\""
+}
+
+model User {
+  id  String @id
+  url String
+}
+`
+	if got := replaceURL(schema, `postgresql://localhost/db?schema="a"`); got != expected {
+		t.Errorf("got\n%s\nexpected\n%s", got, expected)
+	}
+
+	provider, err := datasourceProvider(schema)
+	if err != nil || provider != "postgresql" {
+		t.Errorf("got %q, %v", provider, err)
+	}
+	if _, err := datasourceProvider("model User {\n}\n"); err == nil {
+		t.Errorf("expected an error without a datasource")
+	}
+}
@@ -0,0 +1,72 @@
+package dbtest
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// datasourceProvider returns the provider of the datasource of a Prisma schema, e.g. "sqlite"
+func datasourceProvider(schema string) (string, error) {
+	var provider string
+	forEachDatasourceLine(schema, func(key, value string) string {
+		// ignore trailing comments
+		if fields := strings.Fields(value); key == "provider" && len(fields) > 0 {
+			provider, _ = strconv.Unquote(fields[0])
+		}
+		return ""
+	})
+	if provider == "" {
+		return "", fmt.Errorf("no datasource provider found")
+	}
+	return provider, nil
+}
+
+// replaceURL sets the url and directUrl of the datasource of a Prisma schema to the given url
+func replaceURL(schema string, u string) string {
+	return forEachDatasourceLine(schema, func(key, value string) string {
+		if key == "url" || key == "directUrl" {
+			return fmt.Sprintf("%s = %s", key, strconv.Quote(u))
+		}
+		return ""
+	})
+}
+
+// forEachDatasourceLine calls fn with the key and value of every `key = value` line of the datasource block,
+// and replaces the line if fn returns a non-empty string
+func forEachDatasourceLine(schema string, fn func(key, value string) string) string {
+	lines := strings.Split(schema, "\n")
+	inDatasource := false
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 3 && fields[0] == "datasource" && fields[2] == "{":
+			inDatasource = true
+		case inDatasource && strings.TrimSpace(line) == "}":
+			inDatasource = false
+		case inDatasource:
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			if replaced := fn(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])); replaced != "" {
+				indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+				lines[i] = indent + replaced
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withSchema returns a PostgreSQL url which uses the given database schema
+func withSchema(base string, schema string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("schema", schema)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
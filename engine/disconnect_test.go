@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func waitForActive(t *testing.T, e *QueryEngine, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		e.mu.Lock()
+		active := e.active
+		e.mu.Unlock()
+		if active == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d requests in flight, got %d", n, active)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueryEngine_DisconnectContext(t *testing.T) {
+	e, _ := newFakeEngine(t)
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var v pidResult
+			errs <- e.Do(context.Background(), map[string]interface{}{"query": "sleep"}, &v)
+		}()
+	}
+
+	waitForActive(t, e, n)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	disconnected := make(chan error, 1)
+	go func() {
+		disconnected <- e.DisconnectContext(ctx)
+	}()
+
+	// wait until disconnecting started, then make sure new requests are rejected
+	for {
+		e.mu.Lock()
+		started := e.disconnected
+		e.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var v pidResult
+	if err := e.Do(context.Background(), map[string]interface{}{}, &v); err == nil {
+		t.Errorf("expected requests to be rejected while disconnecting")
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("in-flight request was dropped: %s", err)
+		}
+	}
+
+	if err := <-disconnected; err != nil {
+		t.Fatalf("could not disconnect: %s", err)
+	}
+
+	select {
+	case <-e.exited:
+	default:
+		t.Errorf("expected engine process to have exited")
+	}
+}
+
+func TestQueryEngine_DisconnectContext_timeout(t *testing.T) {
+	t.Setenv(fakeEngineIgnoreInterruptEnv, "1")
+	e, _ := newFakeEngine(t)
+
+	done := make(chan error, 1)
+	go func() {
+		var v pidResult
+		done <- e.Do(context.Background(), map[string]interface{}{"query": "sleep"}, &v)
+	}()
+
+	waitForActive(t, e, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := e.DisconnectContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded for the request still in flight, got %v", err)
+	}
+
+	// the engine ignores the interrupt, so it must have been killed when ctx was done
+	select {
+	case <-e.exited:
+	default:
+		t.Errorf("expected engine process to have been killed")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected engine to be killed when ctx is done, took %s", elapsed)
+	}
+
+	<-done
+}
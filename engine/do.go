@@ -3,20 +3,21 @@ package engine
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
-
-	"github.com/vnsoft2014/prisma-client-go/logger"
 )
 
 var errNotFound = fmt.Errorf("not found; re-upload schema")
 
-func request(ctx context.Context, client *http.Client, method string, url string, payload []byte, apply func(*http.Request)) ([]byte, error) {
-	if logger.Enabled {
-		logger.Debug.Printf("prisma engine payload: `%s`", payload)
+func request(ctx context.Context, log *slog.Logger, client *http.Client, method string, url string, payload []byte, apply func(*http.Request)) ([]byte, error) {
+	debug := log.Enabled(ctx, slog.LevelDebug)
+	if debug {
+		log.DebugContext(ctx, "prisma engine payload", "method", method, "url", url, "payload", string(payload))
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(payload))
@@ -28,10 +29,15 @@ func request(ctx context.Context, client *http.Client, method string, url string
 
 	req = req.WithContext(ctx)
 
+	trace := RequestTraceFromContext(ctx)
+
 	startReq := time.Now()
 	rawResponse, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("raw post: %w", err)
+		if trace != nil {
+			trace.RoundTrip(startReq, time.Now(), 0, err)
+		}
+		return nil, fmt.Errorf("raw post: %w", timeoutError(ctx, err))
 	}
 	defer func() {
 		if err := rawResponse.Body.Close(); err != nil {
@@ -39,33 +45,73 @@ func request(ctx context.Context, client *http.Client, method string, url string
 		}
 	}()
 	reqDuration := time.Since(startReq)
-	logger.Debug.Printf("[timing] query engine raw request took %s", reqDuration)
 
 	responseBody, err := io.ReadAll(rawResponse.Body)
 	if err != nil {
-		return nil, fmt.Errorf("raw read: %w", err)
+		if trace != nil {
+			trace.RoundTrip(startReq, startReq.Add(reqDuration), 0, err)
+		}
+		return nil, fmt.Errorf("raw read: %w", timeoutError(ctx, err))
 	}
 
+	var elapsed time.Duration
+	if elapsedRaw := rawResponse.Header["X-Elapsed"]; len(elapsedRaw) > 0 {
+		micros, _ := strconv.Atoi(elapsedRaw[0])
+		elapsed = time.Duration(micros) * time.Microsecond
+	}
+
+	var statusErr error
 	if rawResponse.StatusCode == http.StatusNotFound {
-		logger.Debug.Printf("status not found with response body %s", responseBody)
-		return nil, errNotFound
+		log.DebugContext(ctx, "status not found", "url", url, "response", string(responseBody))
+		statusErr = errNotFound
+	} else if rawResponse.StatusCode != http.StatusOK && rawResponse.StatusCode != http.StatusCreated {
+		statusErr = fmt.Errorf("http status code %d with response %s", rawResponse.StatusCode, responseBody)
 	}
 
-	if rawResponse.StatusCode != http.StatusOK && rawResponse.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("http status code %d with response %s", rawResponse.StatusCode, responseBody)
+	if trace != nil {
+		trace.RoundTrip(startReq, startReq.Add(reqDuration), elapsed, statusErr)
 	}
 
-	if logger.Enabled {
-		if elapsedRaw := rawResponse.Header["X-Elapsed"]; len(elapsedRaw) > 0 {
-			elapsed, _ := strconv.Atoi(elapsedRaw[0])
-			duration := time.Duration(elapsed) * time.Microsecond
-			logger.Debug.Printf("[timing] elapsed: %s", duration)
+	if statusErr != nil {
+		return nil, statusErr
+	}
 
-			diff := reqDuration - duration
-			logger.Debug.Printf("[timing] just http: %s", diff)
-			logger.Debug.Printf("[timing] http percentage: %.2f%%", float64(diff)/float64(reqDuration)*100)
-		}
+	if debug {
+		// elapsed is the time spent in the engine, the rest of the duration is spent on http
+		log.DebugContext(ctx, "query engine raw request done", "duration", reqDuration, "elapsed", elapsed)
 	}
 
 	return responseBody, nil
 }
+
+// timeoutError makes sure the error of a request which timed out wraps context.DeadlineExceeded, including
+// timeouts of the http client, so callers can distinguish timeouts from engine errors via errors.Is
+func timeoutError(ctx context.Context, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %s", ctxErr, err)
+	}
+	var t interface{ Timeout() bool }
+	if errors.As(err, &t) && t.Timeout() {
+		return fmt.Errorf("%w: %s", context.DeadlineExceeded, err)
+	}
+	return err
+}
+
+// withRequestTimeout applies the request timeout to ctx. The returned function wraps errors of requests which
+// exceeded the request timeout, as opposed to an earlier deadline of ctx, with the timeout.
+func withRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, func(error) error) {
+	if timeout <= 0 {
+		return ctx, func() {}, func(err error) error { return err }
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, func(err error) error {
+		if err != nil && errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil {
+			return fmt.Errorf("request timeout of %s exceeded: %w", timeout, err)
+		}
+		return err
+	}
+}
@@ -11,3 +11,28 @@ type Engine interface {
 	Batch(ctx context.Context, payload interface{}, into interface{}) error
 	Name() string
 }
+
+// Wrapper wraps or replaces the engine of a client, which was created for the given Prisma schema.
+// It is passed to the WithEngine option of the generated client.
+type Wrapper func(schema string, e Engine) Engine
+
+// ContextConnecter is implemented by engines which can stop connecting when a context is done
+type ContextConnecter interface {
+	ConnectContext(ctx context.Context) error
+}
+
+// Connect connects the engine, using ConnectContext if the engine implements it
+func Connect(ctx context.Context, e Engine) error {
+	if c, ok := e.(ContextConnecter); ok {
+		return c.ConnectContext(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return e.Connect()
+}
+
+// GracefulDisconnecter is implemented by engines which can wait for in-flight requests when disconnecting
+type GracefulDisconnecter interface {
+	DisconnectContext(ctx context.Context) error
+}
@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Health describes the state of an engine
+type Health struct {
+	// Name is the name of the engine, e.g. query-engine
+	Name string
+
+	// Version is the version of the engine binary, if known
+	Version string
+
+	// Uptime is the time since the current engine process was started; 0 if it is not running
+	Uptime time.Duration
+
+	// LastError is the last crash or failed health check of the engine, even if it recovered since
+	LastError error
+}
+
+// HealthChecker is implemented by engines which can report their health
+type HealthChecker interface {
+	// Health pings the engine. The health is also returned when the ping failed.
+	Health(ctx context.Context) (*Health, error)
+
+	// Ready returns a channel which is closed when the engine is connected and ready to accept requests.
+	// After a crash or Disconnect, it returns a new channel which is closed once the engine is ready again,
+	// so call it for every check instead of keeping the channel.
+	Ready() <-chan struct{}
+}
+
+// Health pings the engine via its status endpoint
+func (e *QueryEngine) Health(ctx context.Context) (*Health, error) {
+	err := e.ping(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err != nil {
+		e.lastErr = err
+	}
+
+	h := &Health{
+		Name:      e.Name(),
+		Version:   e.version,
+		LastError: e.lastErr,
+	}
+	if !e.startedAt.IsZero() && e.crashErr == nil && !e.disconnected {
+		h.Uptime = time.Since(e.startedAt)
+	}
+
+	if err != nil {
+		return h, fmt.Errorf("engine health check: %w", err)
+	}
+	return h, nil
+}
+
+func (e *QueryEngine) ping(ctx context.Context) error {
+	body, err := e.Request(ctx, "GET", "/status", map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+
+	var response GQLResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("status response unmarshal: %w", err)
+	}
+
+	if len(response.Errors) > 0 {
+		return fmt.Errorf("status gql errors: %+v", response.Errors)
+	}
+
+	return nil
+}
+
+// Ready returns a channel which is closed when the engine is connected, or restarted after a crash
+func (e *QueryEngine) Ready() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.ready == nil {
+		e.ready = make(chan struct{})
+	}
+	return e.ready
+}
+
+// setReady closes the ready channel, so Ready returns immediately until setNotReady is called.
+// It needs to be called with e.mu locked.
+func (e *QueryEngine) setReady() {
+	if e.ready == nil {
+		e.ready = make(chan struct{})
+	}
+	select {
+	case <-e.ready:
+	default:
+		close(e.ready)
+	}
+}
+
+// setNotReady replaces a closed ready channel, so Ready blocks until setReady is called again.
+// It needs to be called with e.mu locked.
+func (e *QueryEngine) setNotReady() {
+	if e.ready == nil {
+		return
+	}
+	select {
+	case <-e.ready:
+		e.ready = make(chan struct{})
+	default:
+	}
+}
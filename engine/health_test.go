@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vnsoft2014/prisma-client-go/binaries"
+)
+
+func TestQueryEngine_Health(t *testing.T) {
+	e := NewQueryEngine("", false)
+
+	select {
+	case <-e.Ready():
+		t.Fatalf("engine must not be ready before connecting")
+	default:
+	}
+
+	e, events := newFakeEngine(t)
+
+	select {
+	case <-e.Ready():
+	case <-time.After(time.Second):
+		t.Fatalf("engine must be ready after connecting")
+	}
+
+	h, err := e.Health(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected health error: %s", err)
+	}
+	if h.Name != "query-engine" || h.Version != binaries.EngineVersion || h.Uptime <= 0 || h.LastError != nil {
+		t.Errorf("unexpected health %+v", h)
+	}
+
+	ready := e.Ready()
+	crash(t, e)
+	waitFor(t, events, HealthFailed)
+
+	select {
+	case <-e.Ready():
+		t.Errorf("engine must not be ready after a crash")
+	default:
+	}
+	select {
+	case <-ready:
+	default:
+		t.Errorf("channels returned before the crash must stay closed")
+	}
+
+	h, err = e.Health(context.Background())
+	if !errors.Is(err, ErrEngineCrashed) {
+		t.Errorf("expected ErrEngineCrashed, got %v", err)
+	}
+	if h.Uptime != 0 || !errors.Is(h.LastError, ErrEngineCrashed) {
+		t.Errorf("unexpected health after crash %+v", h)
+	}
+
+	if err := e.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+}
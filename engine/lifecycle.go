@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,59 +17,168 @@ import (
 	"github.com/vnsoft2014/prisma-client-go/binaries"
 	"github.com/vnsoft2014/prisma-client-go/binaries/platform"
 	"github.com/vnsoft2014/prisma-client-go/binaries/unpack"
-	"github.com/vnsoft2014/prisma-client-go/logger"
 )
 
+// Connect starts the query engine and waits until it is ready. See ConnectContext.
 func (e *QueryEngine) Connect() error {
-	logger.Debug.Printf("ensure query engine binary...")
+	return e.ConnectContext(context.Background())
+}
 
-	_ = godotenv.Load("e2e.env")
-	_ = godotenv.Load("db/e2e.env")
-	_ = godotenv.Load("prisma/e2e.env")
+// ConnectContext starts the query engine and waits until it is ready, or until ctx is done.
+// The connect timeout set via WithConnectTimeout applies in addition to the deadline of ctx.
+func (e *QueryEngine) ConnectContext(ctx context.Context) error {
+	log := e.opts.log()
+	log.Debug("ensure query engine binary...")
 
 	startEngine := time.Now()
 
-	file, err := e.ensure()
+	if e.socket != "" && e.opts.httpClient != nil {
+		return fmt.Errorf("a unix socket can't be used with a custom http client")
+	}
+
+	file, err := e.ensure(ctx)
 	if err != nil {
 		return fmt.Errorf("ensure: %w", err)
 	}
 
-	if err := e.spawn(file); err != nil {
+	cmd, url, err := e.spawn(ctx, file)
+	if err != nil {
 		return fmt.Errorf("spawn: %w", err)
 	}
 
-	logger.Debug.Printf("connecting took %s", time.Since(startEngine))
-	logger.Debug.Printf("connected.")
+	e.mu.Lock()
+	e.stop = make(chan struct{})
+	e.mu.Unlock()
+
+	if !e.start(file, cmd, url) {
+		return fmt.Errorf("engine was disconnected while connecting")
+	}
+
+	log.Debug("connected", "duration", time.Since(startEngine), "url", url)
 
 	return nil
 }
 
+// Disconnect gracefully stops the engine, waiting up to DefaultDisconnectTimeout for in-flight requests.
+// See DisconnectContext.
 func (e *QueryEngine) Disconnect() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultDisconnectTimeout)
+	defer cancel()
+	return e.DisconnectContext(ctx)
+}
+
+// DisconnectContext gracefully stops the engine. New requests are rejected immediately, while requests which
+// are in flight are waited for until ctx is done. The engine process is then interrupted, and killed if it
+// doesn't exit until ctx is done or within killTimeout.
+// An error wrapping ctx.Err() is returned if requests were still in flight when ctx was done.
+func (e *QueryEngine) DisconnectContext(ctx context.Context) error {
+	e.mu.Lock()
+	if e.disconnected {
+		e.mu.Unlock()
+		return nil
+	}
 	e.disconnected = true
-	logger.Debug.Printf("disconnecting...")
+	e.setNotReady()
+	if e.stop != nil {
+		close(e.stop)
+		e.stop = nil
+	}
+	var drained chan struct{}
+	if e.active > 0 {
+		e.drained = make(chan struct{})
+		drained = e.drained
+	}
+	cmd, exited := e.cmd, e.exited
+	e.mu.Unlock()
+
+	log := e.opts.log()
+	log.Debug("disconnecting...")
+
+	var drainErr error
+	if drained != nil {
+		log.Debug("waiting for in-flight requests...")
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			e.mu.Lock()
+			active := e.active
+			e.mu.Unlock()
+			drainErr = fmt.Errorf("%d requests still in flight: %w", active, ctx.Err())
+		}
+	}
+
+	if err := e.terminate(ctx, cmd, exited); err != nil {
+		return err
+	}
+
+	e.http.CloseIdleConnections()
+	if e.socket != "" {
+		_ = os.Remove(e.socket)
+	}
+
+	log.Debug("disconnected")
+	return drainErr
+}
+
+// terminate interrupts the engine process and kills it if it doesn't exit until ctx is done or within killTimeout
+func (e *QueryEngine) terminate(ctx context.Context, cmd *exec.Cmd, exited chan struct{}) error {
+	if cmd == nil {
+		return fmt.Errorf("engine is not connected")
+	}
+
+	select {
+	case <-exited:
+		// the engine crashed and was not restarted, so there is no process to stop
+		return nil
+	default:
+	}
 
 	if platform.Name() == "windows" {
-		if err := e.cmd.Process.Kill(); err != nil {
+		if err := cmd.Process.Kill(); err != nil {
 			return fmt.Errorf("kill process: %w", err)
 		}
+		<-exited
 		return nil
 	}
 
-	if err := e.cmd.Process.Signal(os.Interrupt); err != nil {
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
 		return fmt.Errorf("send signal: %w", err)
 	}
 
-	if err := e.cmd.Wait(); err != nil {
-		if err.Error() != "signal: interrupt" {
+	// the supervisor waits for the process and closes exited
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		e.opts.log().Debug("engine did not exit in time; killing it")
+		_ = cmd.Process.Kill()
+		<-exited
+	case <-time.After(killTimeout):
+		e.opts.log().Debug("engine did not exit in time; killing it", "timeout", killTimeout)
+		_ = cmd.Process.Kill()
+		<-exited
+	}
+
+	e.mu.Lock()
+	err := e.exitErr
+	e.mu.Unlock()
+
+	if err != nil {
+		if msg := err.Error(); msg != "signal: interrupt" && msg != "signal: killed" {
 			return fmt.Errorf("wait for process: %w", err)
 		}
 	}
 
-	logger.Debug.Printf("disconnected.")
 	return nil
 }
 
-func (e *QueryEngine) ensure() (string, error) {
+// DefaultDisconnectTimeout is the maximum amount of time Disconnect waits for in-flight requests
+const DefaultDisconnectTimeout = 10 * time.Second
+
+// killTimeout is the maximum amount of time to wait for the engine to exit after interrupting it
+var killTimeout = 5 * time.Second
+
+func (e *QueryEngine) ensure(ctx context.Context) (string, error) {
+	log := e.opts.log()
 	ensureEngine := time.Now()
 
 	unpackPath := binaries.GlobalUnpackDir(binaries.EngineVersion)
@@ -90,16 +201,25 @@ func (e *QueryEngine) ensure() (string, error) {
 	cacheStatic := path.Join(cachePath, binaries.EngineVersion, name+binaryName)
 	cacheExact := path.Join(cachePath, binaries.EngineVersion, name+exactBinaryName)
 
-	logger.Debug.Printf("checking for local query engine `%s` or `%s`", localStatic, localExact)
-	logger.Debug.Printf("checking for global query engine `%s` or `%s`", globalUnpackStatic, globalUnpackExact)
-	logger.Debug.Printf("checking for cached query engine `%s` or `%s`", cacheStatic, cacheExact)
+	log.Debug("checking for local query engine", "static", localStatic, "exact", localExact)
+	log.Debug("checking for global query engine", "static", globalUnpackStatic, "exact", globalUnpackExact)
+	log.Debug("checking for cached query engine", "static", cacheStatic, "exact", cacheExact)
 
 	// TODO write tests for all cases
 
-	// first, check if the query engine binary is being overridden by PRISMA_QUERY_ENGINE_BINARY
+	// first, check if the query engine binary is being overridden by the engine options or PRISMA_QUERY_ENGINE_BINARY
 	prismaQueryEngineBinary := os.Getenv("PRISMA_QUERY_ENGINE_BINARY")
-	if prismaQueryEngineBinary != "" {
-		logger.Debug.Printf("PRISMA_QUERY_ENGINE_BINARY is defined, using %s", prismaQueryEngineBinary)
+	if e.opts.binaryPath != "" {
+		log.Debug("binary path option is defined", "path", e.opts.binaryPath)
+
+		if _, err := os.Stat(e.opts.binaryPath); err != nil {
+			return "", fmt.Errorf("a query engine binary path was provided, but no query engine was found at %s", e.opts.binaryPath)
+		}
+
+		file = e.opts.binaryPath
+		forceVersion = false
+	} else if prismaQueryEngineBinary != "" {
+		log.Debug("PRISMA_QUERY_ENGINE_BINARY is defined", "path", prismaQueryEngineBinary)
 
 		if _, err := os.Stat(prismaQueryEngineBinary); err != nil {
 			return "", fmt.Errorf("PRISMA_QUERY_ENGINE_BINARY was provided, but no query engine was found at %s", prismaQueryEngineBinary)
@@ -109,11 +229,11 @@ func (e *QueryEngine) ensure() (string, error) {
 		forceVersion = false
 	} else {
 		if qe := os.Getenv(unpack.FileEnv); qe != "" {
-			logger.Debug.Printf("using unpacked file env %s %s", unpack.FileEnv, qe)
+			log.Debug("using unpacked file env", "env", unpack.FileEnv, "path", qe)
 
 			if info, err := os.Stat(qe); err == nil {
 				file = qe
-				logger.Debug.Printf("exact query engine found in working directory: %s %+v", file, info)
+				log.Debug("exact query engine found in working directory", "path", file, "size", info.Size())
 			} else {
 				return "", fmt.Errorf("prisma query engine was expected at %s via FileEnv but was not found", qe)
 			}
@@ -121,22 +241,22 @@ func (e *QueryEngine) ensure() (string, error) {
 
 		if info, err := os.Stat(localExact); err == nil {
 			file = localExact
-			logger.Debug.Printf("exact query engine found in working directory: %s %+v", file, info)
+			log.Debug("exact query engine found in working directory", "path", file, "size", info.Size())
 		} else if info, err = os.Stat(localStatic); err == nil {
 			file = localStatic
-			logger.Debug.Printf("query engine found in working directory: %s %+v", file, info)
+			log.Debug("query engine found in working directory", "path", file, "size", info.Size())
 		} else if info, err = os.Stat(cacheExact); err == nil {
 			file = cacheExact
-			logger.Debug.Printf("query engine found in cache path: %s %+v", file, info)
+			log.Debug("exact query engine found in cache path", "path", file, "size", info.Size())
 		} else if info, err = os.Stat(cacheStatic); err == nil {
 			file = cacheStatic
-			logger.Debug.Printf("exact query engine found in cache path: %s %+v", file, info)
+			log.Debug("query engine found in cache path", "path", file, "size", info.Size())
 		} else if info, err = os.Stat(globalUnpackExact); err == nil {
 			file = globalUnpackExact
-			logger.Debug.Printf("query engine found in global path: %s %+v", file, info)
+			log.Debug("exact query engine found in global path", "path", file, "size", info.Size())
 		} else if info, err = os.Stat(globalUnpackStatic); err == nil {
 			file = globalUnpackStatic
-			logger.Debug.Printf("exact query engine found in global path: %s %+v", file, info)
+			log.Debug("query engine found in global path", "path", file, "size", info.Size())
 		}
 	}
 
@@ -146,79 +266,137 @@ func (e *QueryEngine) ensure() (string, error) {
 	}
 
 	startVersion := time.Now()
-	out, err := exec.Command(file, "--version").Output()
+	out, err := exec.CommandContext(ctx, file, "--version").Output()
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("version check canceled: %w", ctxErr)
+		}
 		return "", fmt.Errorf("version check failed: %w", err)
 	}
-	logger.Debug.Printf("version check took %s", time.Since(startVersion))
+	log.Debug("version check done", "duration", time.Since(startVersion))
+
+	v := strings.TrimSpace(strings.Replace(string(out), "query-engine", "", 1))
+
+	e.mu.Lock()
+	e.version = v
+	e.mu.Unlock()
 
-	if v := strings.TrimSpace(strings.Replace(string(out), "query-engine", "", 1)); binaries.EngineVersion != v {
+	if binaries.EngineVersion != v {
 		note := "Did you forget to run `go run github.com/vnsoft2014/prisma-client-go generate`?"
 		msg := fmt.Errorf("expected query engine version `%s` but got `%s`\n%s", binaries.EngineVersion, v, note)
 		if forceVersion {
 			return "", msg
 		}
 
-		logger.Info.Printf("%s, ignoring since custom query engine was provided", msg)
+		log.Warn("query engine version mismatch, ignoring since custom query engine was provided", "error", msg)
 	}
 
-	logger.Debug.Printf("using query engine at %s", file)
-	logger.Debug.Printf("ensure query engine took %s", time.Since(ensureEngine))
+	log.Debug("using query engine", "path", file, "version", v, "duration", time.Since(ensureEngine))
 
 	return file, nil
 }
 
-func (e *QueryEngine) spawn(file string) error {
-	port, err := getPort()
-	if err != nil {
-		return fmt.Errorf("get free port: %w", err)
+// spawn starts the query engine on a free port or its unix domain socket and waits until it is ready or ctx is done.
+// It returns the process and the url of the engine.
+func (e *QueryEngine) spawn(ctx context.Context, file string) (*exec.Cmd, string, error) {
+	var url string
+	var args []string
+	var addr slog.Attr
+	if e.socket != "" {
+		// a socket left behind by a crashed engine would prevent the new engine from listening
+		if err := os.Remove(e.socket); err != nil && !os.IsNotExist(err) {
+			return nil, "", fmt.Errorf("remove stale socket: %w", err)
+		}
+		// the host is ignored, as the transport always dials the socket
+		url = "http://localhost"
+		args = []string{"--unix-path", e.socket}
+		addr = slog.String("socket", e.socket)
+	} else {
+		port, err := getPort()
+		if err != nil {
+			return nil, "", fmt.Errorf("get free port: %w", err)
+		}
+		url = "http://localhost:" + port
+		args = []string{"-p", port}
+		addr = slog.String("port", port)
 	}
 
-	logger.Debug.Printf("running query-engine on port %s", port)
+	log := e.opts.log().With(addr)
+	log.Debug("running query engine")
 
-	e.url = "http://localhost:" + port
+	cmd := exec.Command(file, append(args, "--enable-raw-queries")...)
 
-	e.cmd = exec.Command(file, "-p", port, "--enable-raw-queries")
+	level := e.opts.engineLogLevel()
 
-	e.cmd.Stdout = os.Stdout
-	e.cmd.Stderr = os.Stderr
+	// the engine logs json lines, which are parsed and passed to the log handler and query hooks
+	logs := &logWriter{
+		log:     e.opts.engineLog().With(addr),
+		onQuery: e.emitQuery,
+	}
+	cmd.Stdout = logs
+	cmd.Stderr = logs
 
-	e.cmd.Env = append(
-		os.Environ(),
+	cmd.Env = append(os.Environ(), dotenv("e2e.env", "db/e2e.env", "prisma/e2e.env")...)
+	cmd.Env = append(
+		cmd.Env,
 		"PRISMA_DML="+e.Schema,
-		"RUST_LOG=error",
+		"RUST_LOG="+rustLog(level),
 		"RUST_LOG_FORMAT=json",
 		"PRISMA_CLIENT_ENGINE_TYPE=binary",
 	)
 
-	// TODO fine tune this using log levels
-	if logger.Enabled {
-		e.cmd.Env = append(
-			e.cmd.Env,
+	if len(e.opts.datasources) > 0 {
+		datasources, err := json.Marshal(e.opts.datasources)
+		if err != nil {
+			return nil, "", fmt.Errorf("marshal datasource overrides: %w", err)
+		}
+		cmd.Env = append(cmd.Env, "OVERWRITE_DATASOURCES="+string(datasources))
+	}
+
+	// queries are logged at info level by the engine
+	if level <= slog.LevelDebug || e.hasQueryHooks() {
+		cmd.Env = append(
+			cmd.Env,
 			"PRISMA_LOG_QUERIES=y",
-			"RUST_LOG=info",
+			"RUST_LOG="+rustLog(slog.LevelInfo),
 		)
 	}
 
-	logger.Debug.Printf("starting engine...")
+	log.Debug("starting engine...")
 
-	if err := e.cmd.Start(); err != nil {
-		return fmt.Errorf("start command: %w", err)
+	if err := cmd.Start(); err != nil {
+		return nil, "", fmt.Errorf("start command: %w", err)
 	}
 
-	logger.Debug.Printf("connecting to engine...")
+	log.Debug("connecting to engine...")
 
-	ctx := context.Background()
+	readyCtx := ctx
+	if e.opts.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		readyCtx, cancel = context.WithTimeout(ctx, e.opts.connectTimeout)
+		defer cancel()
+	}
 
-	// send a basic readiness healthcheck and retry if unsuccessful
+	// send a basic readiness healthcheck and retry if unsuccessful; when a connect timeout or a deadline is set,
+	// retry until it is exceeded instead of a fixed number of times
+	_, deadline := readyCtx.Deadline()
 	var connectErr error
 	var gqlErrors []GQLError
-	for i := 0; i < 100; i++ {
-		body, err := e.Request(ctx, "GET", "/status", map[string]interface{}{})
+	for i := 0; i < 100 || deadline; i++ {
+		if err := readyCtx.Err(); err != nil {
+			if ctx.Err() == nil {
+				connectErr = fmt.Errorf("connect timeout of %s exceeded: %w", e.opts.connectTimeout, err)
+			} else {
+				connectErr = fmt.Errorf("connect canceled: %w", err)
+			}
+			break
+		}
+
+		body, err := e.send(readyCtx, url, "GET", "/status", map[string]interface{}{})
 		if err != nil {
 			connectErr = err
-			logger.Debug.Printf("could not connect; retrying...")
-			time.Sleep(100 * time.Millisecond)
+			log.Debug("could not connect; retrying...", "error", err)
+			sleep(readyCtx, 100*time.Millisecond)
 			continue
 		}
 
@@ -226,15 +404,15 @@ func (e *QueryEngine) spawn(file string) error {
 
 		if err := json.Unmarshal(body, &response); err != nil {
 			connectErr = err
-			logger.Debug.Printf("could not unmarshal response; retrying...")
-			time.Sleep(50 * time.Millisecond)
+			log.Debug("could not unmarshal response; retrying...", "error", err)
+			sleep(readyCtx, 50*time.Millisecond)
 			continue
 		}
 
 		if response.Errors != nil {
 			gqlErrors = response.Errors
-			logger.Debug.Printf("could not connect due to gql errors; retrying...")
-			time.Sleep(50 * time.Millisecond)
+			log.Debug("could not connect due to gql errors; retrying...", "errors", len(response.Errors))
+			sleep(readyCtx, 50*time.Millisecond)
 			continue
 		}
 
@@ -244,12 +422,59 @@ func (e *QueryEngine) spawn(file string) error {
 	}
 
 	if connectErr != nil {
-		return fmt.Errorf("readiness query error: %w", connectErr)
+		kill(cmd)
+		return nil, "", fmt.Errorf("readiness query error: %w", connectErr)
 	}
 
 	if gqlErrors != nil {
-		return fmt.Errorf("readiness gql errors: %+v", gqlErrors)
+		kill(cmd)
+		return nil, "", fmt.Errorf("readiness gql errors: %+v", gqlErrors)
 	}
 
-	return nil
+	return cmd, url, nil
+}
+
+// sleep waits for the given duration, or until ctx is done
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// kill stops an engine process which could not be started properly
+func kill(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+}
+
+// dotenv reads variables from the given env files for the engine process without modifying the environment of the
+// current process, so clients with different datasources don't affect each other. Variables which are already set in
+// the environment or in an earlier file take precedence.
+func dotenv(files ...string) []string {
+	var env []string
+	seen := make(map[string]bool)
+	for _, file := range files {
+		vars, err := godotenv.Read(file)
+		if err != nil {
+			continue
+		}
+
+		keys := make([]string, 0, len(vars))
+		for key := range vars {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if _, ok := os.LookupEnv(key); ok || seen[key] {
+				continue
+			}
+			seen[key] = true
+			env = append(env, key+"="+vars[key])
+		}
+	}
+	return env
 }
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDotenv(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.env")
+	second := filepath.Join(dir, "second.env")
+
+	if err := os.WriteFile(first, []byte("PRISMA_TEST_DOTENV_B=first\nPRISMA_TEST_DOTENV_A=first\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("PRISMA_TEST_DOTENV_A=second\nPRISMA_TEST_DOTENV_C=second\nPRISMA_TEST_DOTENV_SET=second\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PRISMA_TEST_DOTENV_SET", "env")
+
+	got := dotenv(first, filepath.Join(dir, "missing.env"), second)
+	want := []string{
+		"PRISMA_TEST_DOTENV_A=first",
+		"PRISMA_TEST_DOTENV_B=first",
+		"PRISMA_TEST_DOTENV_C=second",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dotenv() = %v, want %v", got, want)
+	}
+
+	if _, ok := os.LookupEnv("PRISMA_TEST_DOTENV_A"); ok {
+		t.Fatalf("dotenv() must not modify the process environment")
+	}
+}
+
+func TestQueryEngine_ConnectContext(t *testing.T) {
+	t.Setenv(fakeEngineEnv, "1")
+	t.Setenv(fakeEngineNotReadyEnv, "1")
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		timeout time.Duration
+		want    error
+		message string
+	}{{
+		name:    "deadline",
+		timeout: 3 * time.Second,
+		want:    context.DeadlineExceeded,
+		message: "connect canceled",
+	}, {
+		name:    "connect timeout",
+		opts:    []Option{WithConnectTimeout(300 * time.Millisecond)},
+		timeout: time.Minute,
+		want:    context.DeadlineExceeded,
+		message: "connect timeout of 300ms exceeded",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewQueryEngine("", false, append(tt.opts, WithBinaryPath(os.Args[0]))...)
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := e.ConnectContext(ctx)
+			if !errors.Is(err, tt.want) || !strings.Contains(err.Error(), tt.message) {
+				t.Fatalf("expected %q error wrapping %v, got %v", tt.message, tt.want, err)
+			}
+			if elapsed := time.Since(start); elapsed > tt.timeout+5*time.Second {
+				t.Errorf("expected connect to stop when the context is done, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestQueryEngine_ConnectContext_canceled(t *testing.T) {
+	t.Setenv(fakeEngineEnv, "1")
+
+	e := NewQueryEngine("", false, WithBinaryPath(os.Args[0]))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := e.ConnectContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected canceled error, got %v", err)
+	}
+}
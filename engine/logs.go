@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vnsoft2014/prisma-client-go/logger"
+)
+
+// QueryEvent describes a database query which was executed by the engine
+type QueryEvent struct {
+	// Timestamp is the time the engine logged the query
+	Timestamp time.Time
+
+	// Query is the query sent to the database, e.g. SQL
+	Query string
+
+	// Params contains the query parameters as a JSON array
+	Params string
+
+	// Duration is the time the database took to execute the query
+	Duration time.Duration
+
+	// Target is the engine module which logged the query
+	Target string
+}
+
+// QueryLogger is implemented by engines which report the database queries they execute
+type QueryLogger interface {
+	// OnQuery registers a function which is called for every database query. It must be registered before connecting.
+	OnQuery(fn func(QueryEvent))
+}
+
+// LogProvider is implemented by engines which log via a logger set for the client
+type LogProvider interface {
+	// Logger returns the logger of the engine
+	Logger() *slog.Logger
+}
+
+// Logger returns the logger of the given engine, or the default logger if the engine doesn't provide one
+func Logger(e Engine) *slog.Logger {
+	if p, ok := e.(LogProvider); ok {
+		return p.Logger()
+	}
+	return logger.Default()
+}
+
+// Logger returns the logger of the client, set via WithLogHandler
+func (e *QueryEngine) Logger() *slog.Logger {
+	return e.opts.log()
+}
+
+// Logger returns the logger of the client, set via WithLogHandler
+func (e *DataProxyEngine) Logger() *slog.Logger {
+	return e.opts.log()
+}
+
+// engineLog is a log line of the engine, which logs json via RUST_LOG_FORMAT=json
+type engineLog struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Target    string                 `json:"target"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// logWriter parses the log lines of the engine process and passes them to a slog logger and query hooks
+type logWriter struct {
+	mu      sync.Mutex
+	buf     []byte
+	log     *slog.Logger
+	onQuery func(QueryEvent)
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.line(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *logWriter) line(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	var entry engineLog
+	if err := json.Unmarshal(line, &entry); err != nil || entry.Level == "" {
+		// not a structured log line, e.g. a panic message
+		w.log.Info(string(line))
+		return
+	}
+
+	timestamp, _ := time.Parse(time.RFC3339Nano, entry.Timestamp)
+
+	if query, ok := entry.Fields["query"].(string); ok {
+		event := QueryEvent{
+			Timestamp: timestamp,
+			Query:     query,
+			Params:    fieldString(entry.Fields["params"]),
+			Duration:  fieldDuration(entry.Fields["duration_ms"]),
+			Target:    entry.Target,
+		}
+		if w.onQuery != nil {
+			w.onQuery(event)
+		}
+		w.log.Debug("query",
+			slog.String("query", event.Query),
+			slog.String("params", event.Params),
+			slog.Duration("duration", event.Duration),
+		)
+		return
+	}
+
+	message := fieldString(entry.Fields["message"])
+	attrs := []slog.Attr{slog.String("target", entry.Target)}
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		if key != "message" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, entry.Fields[key]))
+	}
+	w.log.LogAttrs(context.Background(), engineLevel(entry.Level), message, attrs...)
+}
+
+// engineLevel maps the level of an engine log line to a slog level
+func engineLevel(level string) slog.Level {
+	switch strings.ToUpper(level) {
+	case "TRACE", "DEBUG":
+		return slog.LevelDebug
+	case "WARN":
+		return slog.LevelWarn
+	case "ERROR":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// rustLog returns the RUST_LOG value for a slog level. Engine debug logs are too verbose to be useful,
+// so debug logs of the client result in info logs of the engine.
+func rustLog(level slog.Level) string {
+	switch {
+	case level <= slog.LevelInfo:
+		return "info"
+	case level <= slog.LevelWarn:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+// levelHandler drops records below a minimum level before passing them to the wrapped handler
+type levelHandler struct {
+	slog.Handler
+	level slog.Level
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level && h.Handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
+
+func fieldString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func fieldDuration(v interface{}) time.Duration {
+	var ms float64
+	switch v := v.(type) {
+	case float64:
+		ms = v
+	case string:
+		ms, _ = strconv.ParseFloat(v, 64)
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// OnQuery registers a function which is called for every database query executed by the engine.
+// It must be registered before calling Connect. fn is called from a separate goroutine and should not block.
+func (e *QueryEngine) OnQuery(fn func(QueryEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queryHooks = append(e.queryHooks, fn)
+}
+
+func (e *QueryEngine) hasQueryHooks() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.queryHooks) > 0
+}
+
+func (e *QueryEngine) emitQuery(event QueryEvent) {
+	e.mu.Lock()
+	hooks := e.queryHooks
+	e.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(event)
+	}
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var events []QueryEvent
+	w := &logWriter{
+		log: slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})),
+		onQuery: func(event QueryEvent) {
+			events = append(events, event)
+		},
+	}
+
+	lines := []string{
+		`{"timestamp":"2022-01-01T00:00:00Z","level":"INFO","fields":{"message":"Started query engine http server","ip":"127.0.0.1","port":"4466"},"target":"query_engine::server"}`,
+		`{"timestamp":"2022-01-01T00:00:01Z","level":"INFO","fields":{"query":"SELECT 1","params":"[1]","duration_ms":"1.5"},"target":"quaint::connector::metrics"}`,
+		`{"timestamp":"2022-01-01T00:00:02Z","level":"ERROR","fields":{"message":"connection lost"},"target":"quaint::pooled"}`,
+		`thread 'main' panicked`,
+	}
+
+	// write in chunks which don't align with lines
+	all := strings.Join(lines, "\n") + "\n"
+	for i := 0; i < len(all); i += 7 {
+		end := i + 7
+		if end > len(all) {
+			end = len(all)
+		}
+		if _, err := w.Write([]byte(all[i:end])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantEvents := []QueryEvent{{
+		Timestamp: time.Date(2022, 1, 1, 0, 0, 1, 0, time.UTC),
+		Query:     "SELECT 1",
+		Params:    "[1]",
+		Duration:  1500 * time.Microsecond,
+		Target:    "quaint::connector::metrics",
+	}}
+	if !reflect.DeepEqual(events, wantEvents) {
+		t.Errorf("events = %+v, want %+v", events, wantEvents)
+	}
+
+	wantLogs := []string{
+		`level=INFO msg="Started query engine http server" target=query_engine::server ip=127.0.0.1 port=4466`,
+		`level=DEBUG msg=query query="SELECT 1" params=[1] duration=1.5ms`,
+		`level=ERROR msg="connection lost" target=quaint::pooled`,
+		`level=INFO msg="thread 'main' panicked"`,
+	}
+	if got := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(got, wantLogs) {
+		t.Errorf("logs = %q, want %q", got, wantLogs)
+	}
+}
+
+func TestRustLog(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  string
+	}{
+		{slog.LevelDebug, "info"},
+		{slog.LevelInfo, "info"},
+		{slog.LevelWarn, "warn"},
+		{slog.LevelError, "error"},
+	}
+	for _, tt := range tests {
+		if got := rustLog(tt.level); got != tt.want {
+			t.Errorf("rustLog(%s) = %s, want %s", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestQueryEngine_OnQuery(t *testing.T) {
+	t.Setenv(fakeEngineEnv, "1")
+
+	events := make(chan QueryEvent, 1)
+	e := NewQueryEngine("", false, WithBinaryPath(os.Args[0]))
+	e.OnQuery(func(event QueryEvent) {
+		events <- event
+	})
+	if err := e.Connect(); err != nil {
+		t.Fatalf("could not connect: %s", err)
+	}
+	defer func() {
+		if err := e.Disconnect(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	var v pidResult
+	if err := e.Do(context.Background(), map[string]interface{}{}, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Query != "SELECT 1" || event.Duration != 2*time.Millisecond {
+			t.Errorf("unexpected query event %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for query event")
+	}
+}
@@ -0,0 +1,364 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// matches returns whether a record matches a where filter
+func (e *Engine) matches(m *model, r record, where map[string]interface{}) (bool, error) {
+	for key, cond := range where {
+		ok, err := e.matchesKey(m, r, key, cond)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (e *Engine) matchesKey(m *model, r record, key string, cond interface{}) (bool, error) {
+	switch key {
+	case "AND":
+		for _, c := range toList(cond) {
+			ok, err := e.matches(m, r, object(c))
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case "OR":
+		for _, c := range toList(cond) {
+			ok, err := e.matches(m, r, object(c))
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case "NOT":
+		for _, c := range toList(cond) {
+			ok, err := e.matches(m, r, object(c))
+			if err != nil || ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	f := m.field(key)
+	if f == nil {
+		// compound unique keys, e.g. `email_name:{email:"a",name:"b",}`
+		compound, ok := cond.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("unknown field %s.%s", m.name, key)
+		}
+		return e.matches(m, r, compound)
+	}
+	if f.relation {
+		rel, err := e.schema.relation(m, f)
+		if err != nil {
+			return false, err
+		}
+		return e.matchesRelation(rel, r, cond)
+	}
+	return matchesScalar(f, r[f.name], cond)
+}
+
+func (e *Engine) matchesRelation(rel *relation, r record, cond interface{}) (bool, error) {
+	related := e.related(rel, r)
+
+	if rel.field.list {
+		for op, c := range object(cond) {
+			where := object(c)
+			count := 0
+			for _, rr := range related {
+				ok, err := e.matches(rel.to, rr, where)
+				if err != nil {
+					return false, err
+				}
+				if ok {
+					count++
+				}
+			}
+			switch op {
+			case "some":
+				if count == 0 {
+					return false, nil
+				}
+			case "every":
+				if count != len(related) {
+					return false, nil
+				}
+			case "none":
+				if count != 0 {
+					return false, nil
+				}
+			default:
+				return false, fmt.Errorf("unsupported relation filter %s on %s.%s", op, rel.from.name, rel.field.name)
+			}
+		}
+		return true, nil
+	}
+
+	var target record
+	if len(related) > 0 {
+		target = related[0]
+	}
+
+	conds := object(cond)
+	_, hasIs := conds["is"]
+	_, hasIsNot := conds["isNot"]
+	if cond == nil || (!hasIs && !hasIsNot) {
+		conds = map[string]interface{}{"is": cond}
+	}
+	for op, c := range conds {
+		var ok bool
+		if c == nil {
+			ok = target == nil
+		} else if target != nil {
+			var err error
+			if ok, err = e.matches(rel.to, target, object(c)); err != nil {
+				return false, err
+			}
+		}
+		if op == "isNot" {
+			ok = !ok
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchesScalar(f *field, value interface{}, cond interface{}) (bool, error) {
+	ops, ok := cond.(map[string]interface{})
+	if !ok || f.typ == "Json" && !isFilter(ops) {
+		expected, err := coerce(f, cond)
+		if err != nil {
+			return false, err
+		}
+		return equal(value, expected), nil
+	}
+
+	insensitive := ops["mode"] == "insensitive"
+	fold := func(v interface{}) interface{} {
+		if s, ok := v.(string); ok && insensitive {
+			return strings.ToLower(s)
+		}
+		return v
+	}
+
+	for op, operand := range ops {
+		var ok bool
+		switch op {
+		case "mode":
+			continue
+		case "not":
+			if nested, isFilter := operand.(map[string]interface{}); isFilter {
+				matched, err := matchesScalar(f, value, nested)
+				if err != nil {
+					return false, err
+				}
+				ok = !matched
+			} else {
+				expected, err := coerce(f, operand)
+				if err != nil {
+					return false, err
+				}
+				// like in SQL, NULL is neither equal nor unequal to a value
+				switch {
+				case expected == nil:
+					ok = value != nil
+				case value == nil:
+					ok = false
+				default:
+					ok = !equal(fold(value), fold(expected))
+				}
+			}
+		case "has", "hasSome", "hasEvery", "isEmpty":
+			var err error
+			if ok, err = matchesList(f, value, op, operand); err != nil {
+				return false, err
+			}
+		default:
+			scalar := *f
+			scalar.list = false
+			expected := operand
+			if op != "in" && op != "notIn" {
+				var err error
+				if expected, err = coerce(&scalar, operand); err != nil {
+					return false, err
+				}
+			}
+			switch op {
+			case "equals":
+				ok = equal(fold(value), fold(expected))
+			case "in", "notIn":
+				found := false
+				for _, item := range toList(operand) {
+					c, err := coerce(&scalar, item)
+					if err != nil {
+						return false, err
+					}
+					if equal(fold(value), fold(c)) {
+						found = true
+					}
+				}
+				ok = value != nil && found == (op == "in")
+			case "lt", "lte", "gt", "gte":
+				if value == nil || expected == nil {
+					break
+				}
+				c := compare(value, expected)
+				ok = op == "lt" && c < 0 || op == "lte" && c <= 0 || op == "gt" && c > 0 || op == "gte" && c >= 0
+			case "contains", "startsWith", "endsWith":
+				s, isString := fold(value).(string)
+				sub, _ := fold(expected).(string)
+				if !isString {
+					break
+				}
+				ok = op == "contains" && strings.Contains(s, sub) ||
+					op == "startsWith" && strings.HasPrefix(s, sub) ||
+					op == "endsWith" && strings.HasSuffix(s, sub)
+			default:
+				return false, fmt.Errorf("unsupported filter %s on %s", op, f.name)
+			}
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchesList(f *field, value interface{}, op string, operand interface{}) (bool, error) {
+	items := toList(value)
+	scalar := *f
+	scalar.list = false
+	contains := func(v interface{}) (bool, error) {
+		c, err := coerce(&scalar, v)
+		if err != nil {
+			return false, err
+		}
+		for _, item := range items {
+			if equal(item, c) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	switch op {
+	case "has":
+		return contains(operand)
+	case "hasSome", "hasEvery":
+		for _, v := range toList(operand) {
+			found, err := contains(v)
+			if err != nil {
+				return false, err
+			}
+			if found && op == "hasSome" {
+				return true, nil
+			}
+			if !found && op == "hasEvery" {
+				return false, nil
+			}
+		}
+		return op == "hasEvery", nil
+	default:
+		return (len(items) == 0) == (operand == true), nil
+	}
+}
+
+// isFilter returns whether a json value is a filter instead of a json object
+func isFilter(ops map[string]interface{}) bool {
+	_, ok := ops["equals"]
+	_, not := ops["not"]
+	return ok || not
+}
+
+// sortRecords sorts records by an orderBy argument, e.g. `[{email:"asc"},{author:{name:"desc"}},]`
+func (e *Engine) sortRecords(m *model, rows []record, orderBy interface{}) error {
+	items := toList(orderBy)
+	if len(items) == 0 {
+		return nil
+	}
+
+	var err error
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, item := range items {
+			c, cmpErr := e.compareBy(m, rows[i], rows[j], object(item))
+			if cmpErr != nil {
+				err = cmpErr
+				return false
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return err
+}
+
+func (e *Engine) compareBy(m *model, a, b record, order map[string]interface{}) (int, error) {
+	for key, dir := range order {
+		f := m.field(key)
+		if f == nil {
+			return 0, fmt.Errorf("unknown field %s.%s", m.name, key)
+		}
+
+		var c int
+		direction := dir
+		if f.relation {
+			rel, err := e.schema.relation(m, f)
+			if err != nil {
+				return 0, err
+			}
+			ra, rb := e.related(rel, a), e.related(rel, b)
+			nested := object(dir)
+			if countDir, ok := nested["_count"]; ok {
+				c, direction = sign(float64(len(ra)-len(rb))), countDir
+			} else {
+				if len(ra) == 0 || len(rb) == 0 {
+					c = sign(float64(len(ra) - len(rb)))
+				} else if c, err = e.compareBy(rel.to, ra[0], rb[0], nested); err != nil {
+					return 0, err
+				}
+				direction = "asc"
+			}
+		} else {
+			if o, ok := dir.(map[string]interface{}); ok {
+				direction = o["sort"]
+			}
+			va, vb := a[key], b[key]
+			switch {
+			case va == nil && vb == nil:
+				c = 0
+			case va == nil:
+				c = -1
+			case vb == nil:
+				c = 1
+			default:
+				c = compare(va, vb)
+			}
+		}
+
+		if direction == "desc" {
+			c = -c
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+	return 0, nil
+}
+
+// object returns the value as an object, or an empty object if it is not one
+func object(v interface{}) map[string]interface{} {
+	if o, ok := v.(map[string]interface{}); ok {
+		return o
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,465 @@
+// Package memory implements an engine which runs the queries of the generated client against in-memory tables
+// instead of a query engine and database, so unit tests can read their own writes without any external process.
+//
+// The tables are derived from the models of the Prisma schema. The engine supports findUnique, findFirst and
+// findMany with where, orderBy, skip, take, cursor and distinct, createOne, createMany, updateOne, updateMany,
+// deleteOne, deleteMany and upsertOne including nested relation writes, relation filters and fetching relations
+// via With, as well as transactions. Aggregations, raw queries and interactive transactions are not supported.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+// ErrUnsupported is returned for queries which the memory engine can't run, e.g. raw queries
+var ErrUnsupported = errors.New("not supported by the memory engine")
+
+// New returns an engine which stores the records of the models of the given Prisma schema in memory.
+// The schema is parsed when connecting.
+func New(schema string) *Engine {
+	return &Engine{
+		source: schema,
+	}
+}
+
+// Wrap replaces the engine of a client with a memory engine for its schema. It is an engine.Wrapper, e.g.
+//
+//	client := db.NewClient(db.WithEngine(memory.Wrap))
+func Wrap(schema string, _ engine.Engine) engine.Engine {
+	return New(schema)
+}
+
+type Engine struct {
+	source string
+	schema *schema
+
+	mu    sync.Mutex
+	state *state
+}
+
+// state contains all stored data; it is copied for transactions so they can be rolled back
+type state struct {
+	// tables contains the records of each model in insertion order
+	tables map[string][]record
+	// joins contains the id pairs of many to many relations
+	joins map[string][][2]string
+	// sequences contains the last value of each autoincrement field
+	sequences map[string]int64
+}
+
+func (s *state) clone() *state {
+	c := &state{
+		tables:    make(map[string][]record, len(s.tables)),
+		joins:     make(map[string][][2]string, len(s.joins)),
+		sequences: make(map[string]int64, len(s.sequences)),
+	}
+	for k, v := range s.tables {
+		c.tables[k] = append([]record(nil), v...)
+	}
+	for k, v := range s.joins {
+		c.joins[k] = append([][2]string(nil), v...)
+	}
+	for k, v := range s.sequences {
+		c.sequences[k] = v
+	}
+	return c
+}
+
+func (e *Engine) Name() string {
+	return "memory"
+}
+
+// Connect parses the schema. Data is kept when connecting again.
+func (e *Engine) Connect() error {
+	s, err := parseSchema(e.source)
+	if err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.schema = s
+	if e.state == nil {
+		e.state = &state{
+			tables:    map[string][]record{},
+			joins:     map[string][][2]string{},
+			sequences: map[string]int64{},
+		}
+	}
+	return nil
+}
+
+func (e *Engine) Disconnect() error {
+	return nil
+}
+
+func (e *Engine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	req := payload.(engine.GQLRequest)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state == nil {
+		return fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
+	}
+
+	op, err := parseOperation(req.Query)
+	if err != nil {
+		return convertError(err)
+	}
+
+	// nested writes are atomic
+	var snapshot *state
+	if op.write() {
+		snapshot = e.state.clone()
+	}
+	result, err := e.run(op)
+	if err != nil {
+		if snapshot != nil {
+			e.state = snapshot
+		}
+		return convertError(err)
+	}
+
+	if err := json.Unmarshal(result, v); err != nil {
+		return fmt.Errorf("json data result unmarshal: %w", err)
+	}
+	return nil
+}
+
+// Batch runs the queries of a transaction. If a query fails, all changes of the transaction are rolled back.
+func (e *Engine) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	req := payload.(engine.GQLBatchRequest)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state == nil {
+		return fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
+	}
+
+	b, err := json.Marshal(e.batch(req.Batch))
+	if err != nil {
+		return fmt.Errorf("json batch response marshal: %w", err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("json body unmarshal: %w", err)
+	}
+	return nil
+}
+
+// batch runs the queries of a transaction and returns the response of the query engine
+func (e *Engine) batch(queries []engine.GQLRequest) engine.GQLBatchResponse {
+	ops := make([]*operation, len(queries))
+	write := false
+	for i, q := range queries {
+		op, err := parseOperation(q.Query)
+		if err != nil {
+			return engine.GQLBatchResponse{Errors: []engine.GQLError{batchError(i, err)}}
+		}
+		ops[i] = op
+		write = write || op.write()
+	}
+
+	// only transactions which write need to be rolled back
+	var snapshot *state
+	if write {
+		snapshot = e.state.clone()
+	}
+
+	var response engine.GQLBatchResponse
+	for i, op := range ops {
+		result, err := e.run(op)
+		if err != nil {
+			if snapshot != nil {
+				e.state = snapshot
+			}
+			return engine.GQLBatchResponse{Errors: []engine.GQLError{batchError(i, err)}}
+		}
+		var inner engine.GQLResponse
+		inner.Data.Result = result
+		response.Result = append(response.Result, inner)
+	}
+	return response
+}
+
+// run runs a single query and returns its result
+func (e *Engine) run(op *operation) (json.RawMessage, error) {
+	if op.model == "" {
+		return nil, fmt.Errorf("%s: %w", op.method, ErrUnsupported)
+	}
+	m, ok := e.schema.models[op.model]
+	if !ok {
+		return nil, fmt.Errorf("unknown model %s", op.model)
+	}
+
+	var result interface{}
+	var err error
+	switch op.method {
+	case "findUnique", "findFirst":
+		rows, err := e.find(m, e.state.tables[m.name], op.args)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) > 0 {
+			result, err = e.render(m, rows[0], op.output)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case "findMany":
+		rows, err := e.find(m, e.state.tables[m.name], op.args)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = e.renderAll(m, rows, op.output); err != nil {
+			return nil, err
+		}
+	default:
+		if result, err = e.write(m, op); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(result)
+}
+
+// find returns the records matching the where, orderBy, cursor, skip, take and distinct arguments
+func (e *Engine) find(m *model, rows []record, args map[string]interface{}) ([]record, error) {
+	var result []record
+	where := object(args["where"])
+	for _, r := range rows {
+		ok, err := e.matches(m, r, where)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, r)
+		}
+	}
+
+	if err := e.sortRecords(m, result, args["orderBy"]); err != nil {
+		return nil, err
+	}
+
+	// like the query engine, distinct is applied before paging
+	if distinct, ok := args["distinct"]; ok {
+		result = distinctRecords(result, toList(distinct))
+	}
+
+	take := -1
+	backwards := false
+	if v, ok := args["take"]; ok {
+		n, err := toInt(v)
+		if err != nil {
+			return nil, fmt.Errorf("take: %w", err)
+		}
+		take = n
+		if n < 0 {
+			// a negative take pages backwards from the cursor or the end
+			backwards = true
+			take = -n
+			reverse(result)
+		}
+	}
+
+	if cursor, ok := args["cursor"]; ok {
+		start := -1
+		for i, r := range result {
+			ok, err := e.matches(m, r, object(cursor))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				start = i
+				break
+			}
+		}
+		if start == -1 {
+			return nil, nil
+		}
+		result = result[start:]
+	}
+
+	if v, ok := args["skip"]; ok {
+		n, err := toInt(v)
+		if err != nil {
+			return nil, fmt.Errorf("skip: %w", err)
+		}
+		if n > len(result) {
+			n = len(result)
+		}
+		result = result[n:]
+	}
+
+	if take >= 0 && take < len(result) {
+		result = result[:take]
+	}
+	if backwards {
+		reverse(result)
+	}
+
+	return result, nil
+}
+
+func distinctRecords(rows []record, fields []interface{}) []record {
+	seen := map[string]bool{}
+	var result []record
+	for _, r := range rows {
+		var key []interface{}
+		for _, f := range fields {
+			name, _ := f.(string)
+			key = append(key, r[name])
+		}
+		b, _ := json.Marshal(key)
+		if seen[string(b)] {
+			continue
+		}
+		seen[string(b)] = true
+		result = append(result, r)
+	}
+	return result
+}
+
+func reverse(rows []record) {
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+}
+
+// render returns the selected fields of a record, including selected relations
+func (e *Engine) render(m *model, r record, output []selection) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(output))
+	for _, sel := range output {
+		if sel.name == "_count" {
+			counts := map[string]interface{}{}
+			for _, c := range sel.output {
+				f := m.field(c.name)
+				if f == nil || !f.relation {
+					return nil, fmt.Errorf("unknown relation %s.%s", m.name, c.name)
+				}
+				rel, err := e.schema.relation(m, f)
+				if err != nil {
+					return nil, err
+				}
+				rows, err := e.find(rel.to, e.related(rel, r), c.args)
+				if err != nil {
+					return nil, err
+				}
+				counts[c.name] = len(rows)
+			}
+			result[sel.name] = counts
+			continue
+		}
+
+		f := m.field(sel.name)
+		if f == nil {
+			return nil, fmt.Errorf("unknown field %s.%s", m.name, sel.name)
+		}
+
+		if !f.relation {
+			result[f.name] = outputValue(f, r[f.name])
+			continue
+		}
+
+		rel, err := e.schema.relation(m, f)
+		if err != nil {
+			return nil, err
+		}
+		related := e.related(rel, r)
+		if f.list {
+			rows, err := e.find(rel.to, related, sel.args)
+			if err != nil {
+				return nil, err
+			}
+			if result[f.name], err = e.renderAll(rel.to, rows, sel.output); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(related) == 0 {
+			result[f.name] = nil
+			continue
+		}
+		if result[f.name], err = e.render(rel.to, related[0], sel.output); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (e *Engine) renderAll(m *model, rows []record, output []selection) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(rows))
+	for _, r := range rows {
+		v, err := e.render(m, r, output)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+// related returns the records of a relation of a record
+func (e *Engine) related(rel *relation, r record) []record {
+	if rel.manyToMany {
+		id := key(r, rel.fromFields)
+		var ids []string
+		for _, pair := range e.state.joins[rel.key] {
+			if rel.first && pair[0] == id {
+				ids = append(ids, pair[1])
+			}
+			if !rel.first && pair[1] == id {
+				ids = append(ids, pair[0])
+			}
+		}
+		var result []record
+		for _, rr := range e.state.tables[rel.to.name] {
+			for _, other := range ids {
+				if key(rr, rel.toFields) == other {
+					result = append(result, rr)
+				}
+			}
+		}
+		return result
+	}
+
+	for _, f := range rel.fromFields {
+		if r[f] == nil {
+			return nil
+		}
+	}
+	var result []record
+	for _, rr := range e.state.tables[rel.to.name] {
+		if joined(r, rel.fromFields, rr, rel.toFields) {
+			result = append(result, rr)
+		}
+	}
+	return result
+}
+
+// joined returns whether a[aFields[i]] == b[bFields[i]] for all fields
+func joined(a record, aFields []string, b record, bFields []string) bool {
+	for i := range aFields {
+		if a[aFields[i]] == nil || !equal(a[aFields[i]], b[bFields[i]]) {
+			return false
+		}
+	}
+	return true
+}
+
+// key returns a string which identifies the values of the given fields of a record
+func key(r record, fields []string) string {
+	values := make([]interface{}, len(fields))
+	for i, f := range fields {
+		values[i] = r[f]
+	}
+	b, _ := json.Marshal(values)
+	return string(b)
+}
@@ -0,0 +1,245 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
+)
+
+const testSchema = `
+datasource db {
+  provider = "sqlite"
+  url      = "file:dev.db"
+}
+
+model User {
+  id    String  @id @default(cuid())
+  email String  @unique // the email
+  name  String?
+  age   Int     @default(0)
+  posts Post[]
+  tags  Tag[]
+}
+
+model Post {
+  id       Int     @id @default(autoincrement())
+  title    String
+  views    Int     @default(0)
+  author   User    @relation(fields: [authorID], references: [id], onDelete: Cascade)
+  authorID String
+}
+
+model Tag {
+  name  String @id
+  users User[]
+}
+`
+
+func connect(t *testing.T) *Engine {
+	t.Helper()
+	e := New(testSchema)
+	if err := e.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func do(t *testing.T, e *Engine, query string) (interface{}, error) {
+	t.Helper()
+	var v interface{}
+	err := e.Do(context.Background(), engine.GQLRequest{Query: query}, &v)
+	return v, err
+}
+
+func mustDo(t *testing.T, e *Engine, query string) string {
+	t.Helper()
+	v, err := do(t, e, query)
+	if err != nil {
+		t.Fatalf("%s: %s", query, err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestParseOperation(t *testing.T) {
+	op, err := parseOperation(`query {result: findManyPost(where:{title:{contains:"a,}",},"views":{gt:3,},},take:-2,) {id author (where:{id:"x",},){email }}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if op.method != "findMany" || op.model != "Post" {
+		t.Errorf("got %s %s", op.method, op.model)
+	}
+	where := op.args["where"].(map[string]interface{})
+	if contains := where["title"].(map[string]interface{})["contains"]; contains != "a,}" {
+		t.Errorf("contains = %v", contains)
+	}
+	if gt := where["views"].(map[string]interface{})["gt"]; gt != json.Number("3") {
+		t.Errorf("gt = %v", gt)
+	}
+	if len(op.output) != 2 || op.output[1].name != "author" || op.output[1].output[0].name != "email" {
+		t.Errorf("output = %+v", op.output)
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	s, err := parseSchema(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := s.models["User"]
+	if !reflect.DeepEqual(user.uniques, [][]string{{"id"}, {"email"}}) {
+		t.Errorf("uniques = %v", user.uniques)
+	}
+
+	posts, err := s.relation(user, user.field("posts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts.owner || posts.manyToMany || posts.fromFields[0] != "id" || posts.toFields[0] != "authorID" {
+		t.Errorf("posts = %+v", posts)
+	}
+
+	tags, err := s.relation(user, user.field("tags"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tags.manyToMany || tags.key != "TagToUser" || tags.first {
+		t.Errorf("tags = %+v", tags)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	var wrap engine.Wrapper = Wrap
+	if _, ok := wrap(testSchema, nil).(*Engine); !ok {
+		t.Errorf("expected the engine to be replaced with a memory engine")
+	}
+}
+
+func TestEngine(t *testing.T) {
+	e := connect(t)
+
+	mustDo(t, e, `mutation {result: createOneUser(data:{id:"a",email:"a@x",age:30,posts:{create:[{title:"one",views:5,},{title:"two",views:7,},],},},) {id }}`)
+	mustDo(t, e, `mutation {result: createOneUser(data:{id:"b",email:"b@x",tags:{create:{name:"go",},},},) {id }}`)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{{
+		name:  "find unique with relation",
+		query: `query {result: findUniqueUser(where:{email:"a@x",},) {id name posts (where:{views:{gt:5,},},){title }}}`,
+		want:  `{"id":"a","name":null,"posts":[{"title":"two"}]}`,
+	}, {
+		name:  "find unique not found",
+		query: `query {result: findUniqueUser(where:{email:"c@x",},) {id }}`,
+		want:  `null`,
+	}, {
+		name:  "find many with order, skip and take",
+		query: `query {result: findManyPost(orderBy:[{views:"desc"},],skip:1,take:1,) {title author {email }}}`,
+		want:  `[{"author":{"email":"a@x"},"title":"one"}]`,
+	}, {
+		name:  "find many with cursor",
+		query: `query {result: findManyPost(orderBy:[{id:"asc"},],cursor:{id:2,},) {id }}`,
+		want:  `[{"id":2}]`,
+	}, {
+		name:  "distinct before skip",
+		query: `query {result: findManyPost(orderBy:[{id:"asc"},],distinct:[authorID],skip:1,) {id }}`,
+		want:  `[]`,
+	}, {
+		name:  "relation filters",
+		query: `query {result: findManyUser(where:{posts:{none:{},},tags:{some:{name:{equals:"go",},},},},) {id tags {name }}}`,
+		want:  `[{"id":"b","tags":[{"name":"go"}]}]`,
+	}, {
+		name:  "update",
+		query: `mutation {result: updateOneUser(where:{id:"a",},data:{name:{set:"A",},age:{increment:2,},},) {name age }}`,
+		want:  `{"age":32,"name":"A"}`,
+	}, {
+		name:  "update many",
+		query: `mutation {result: updateManyPost(where:{author:{is:{id:{equals:"a",},},},},data:{views:{set:1,},},) {count }}`,
+		want:  `{"count":2}`,
+	}, {
+		name:  "upsert",
+		query: `mutation {result: upsertOneUser(where:{email:"c@x",},create:{id:"c",email:"c@x",},update:{},) {id age }}`,
+		want:  `{"age":0,"id":"c"}`,
+	}, {
+		name:  "delete cascades",
+		query: `mutation {result: deleteOneUser(where:{id:"a",},) {id _count {posts }}}`,
+		want:  `{"_count":{"posts":2},"id":"a"}`,
+	}, {
+		name:  "delete many",
+		query: `mutation {result: deleteManyPost(where:{},) {count }}`,
+		want:  `{"count":0}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustDo(t, e, tt.query); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_Errors(t *testing.T) {
+	e := connect(t)
+	mustDo(t, e, `mutation {result: createOneUser(data:{email:"a@x",},) {id }}`)
+
+	_, err := do(t, e, `mutation {result: createOneUser(data:{email:"a@x",},) {id }}`)
+	if u, ok := types.CheckUniqueConstraint(err); !ok || !reflect.DeepEqual(u.Fields, []string{"email"}) {
+		t.Errorf("expected a unique constraint error, got %v", err)
+	}
+
+	_, err = do(t, e, `mutation {result: updateOneUser(where:{email:"b@x",},data:{},) {id }}`)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// the post is not created if connecting the author fails
+	_, err = do(t, e, `mutation {result: createOnePost(data:{title:"x",author:{connect:{id:"b",},},},) {id }}`)
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if got := mustDo(t, e, `query {result: findManyPost() {id }}`); got != `[]` {
+		t.Errorf("got %s", got)
+	}
+
+	_, err = do(t, e, `query {result: aggregateUser() {_count {_all }}}`)
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestEngine_Batch(t *testing.T) {
+	e := connect(t)
+
+	var result engine.GQLBatchResponse
+	err := e.Batch(context.Background(), engine.GQLBatchRequest{
+		Batch: []engine.GQLRequest{
+			{Query: `mutation {result: createOneUser(data:{email:"a@x",},) {email }}`},
+			{Query: `mutation {result: createOneUser(data:{email:"a@x",},) {email }}`},
+		},
+		Transaction: true,
+	}, &result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected an error, got %+v", result)
+	}
+	if idx := result.Errors[0].UserFacingError.Meta["batch_request_idx"]; idx != float64(1) {
+		t.Errorf("batch_request_idx = %v", idx)
+	}
+	if got := mustDo(t, e, `query {result: findManyUser() {email }}`); got != `[]` {
+		t.Errorf("expected the transaction to be rolled back, got %s", got)
+	}
+}
@@ -0,0 +1,290 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// operation is a parsed query as built by builder.Query.Build,
+// e.g. `query {result: findUniqueUser(where:{id:"a",},) {id email }}`
+type operation struct {
+	method string
+	model  string
+	args   map[string]interface{}
+	output []selection
+}
+
+// selection is a selected output field, e.g. `posts (take:2,){id title }`
+type selection struct {
+	name   string
+	args   map[string]interface{}
+	output []selection
+}
+
+// methods are the query engine methods, which are prefixed to the model name
+var methods = []string{
+	"findUnique", "findFirst", "findMany",
+	"createOne", "createMany",
+	"updateOne", "updateMany",
+	"deleteOne", "deleteMany",
+	"upsertOne",
+	"aggregate", "groupBy",
+	"executeRaw", "queryRaw",
+}
+
+// write returns whether the operation can change data
+func (op *operation) write() bool {
+	switch op.method {
+	case "findUnique", "findFirst", "findMany", "aggregate", "groupBy", "queryRaw":
+		return false
+	default:
+		return true
+	}
+}
+
+type parser struct {
+	s   string
+	pos int
+}
+
+func parseOperation(query string) (*operation, error) {
+	p := &parser{s: query}
+	op, err := p.operation()
+	if err != nil {
+		return nil, fmt.Errorf("parse query %q: %w", query, err)
+	}
+	return op, nil
+}
+
+func (p *parser) operation() (*operation, error) {
+	// skip the operation type and name
+	i := strings.IndexByte(p.s, '{')
+	if i == -1 {
+		return nil, fmt.Errorf("expected {")
+	}
+	p.pos = i + 1
+
+	name := p.ident()
+	if name != "result" {
+		return nil, fmt.Errorf("expected result alias at %d", p.pos)
+	}
+	if err := p.expect(':'); err != nil {
+		return nil, err
+	}
+
+	op := &operation{}
+	action := p.ident()
+	for _, method := range methods {
+		if strings.HasPrefix(action, method) {
+			op.method = method
+			op.model = strings.TrimPrefix(action, method)
+			break
+		}
+	}
+	if op.method == "" {
+		return nil, fmt.Errorf("unknown method %q", action)
+	}
+
+	var err error
+	if op.args, err = p.args(); err != nil {
+		return nil, err
+	}
+	if op.output, err = p.selections(); err != nil {
+		return nil, err
+	}
+	if err := p.expect('}'); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// args parses optional arguments in parentheses
+func (p *parser) args() (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	if !p.consume('(') {
+		return args, nil
+	}
+	for !p.consume(')') {
+		key := p.ident()
+		if key == "" {
+			return nil, fmt.Errorf("expected argument name at %d", p.pos)
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args[key] = value
+		p.consume(',')
+	}
+	return args, nil
+}
+
+// selections parses optional output fields in braces
+func (p *parser) selections() ([]selection, error) {
+	var result []selection
+	if !p.consume('{') {
+		return nil, nil
+	}
+	for !p.consume('}') {
+		sel := selection{name: p.ident()}
+		if sel.name == "" {
+			return nil, fmt.Errorf("expected field name at %d", p.pos)
+		}
+		var err error
+		if sel.args, err = p.args(); err != nil {
+			return nil, err
+		}
+		if sel.output, err = p.selections(); err != nil {
+			return nil, err
+		}
+		result = append(result, sel)
+	}
+	return result, nil
+}
+
+// value parses an object, a list or a JSON value. Object keys are usually not quoted.
+func (p *parser) value() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+
+	switch p.s[p.pos] {
+	case '{':
+		p.pos++
+		object := map[string]interface{}{}
+		for !p.consume('}') {
+			var key string
+			if p.peek() == '"' {
+				v, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = v.(string)
+			} else {
+				key = p.ident()
+			}
+			if key == "" {
+				return nil, fmt.Errorf("expected object key at %d", p.pos)
+			}
+			if err := p.expect(':'); err != nil {
+				return nil, err
+			}
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			// repeated keys overwrite previous ones, e.g. `name:{set:null,set:"a",}`
+			object[key] = value
+			p.consume(',')
+		}
+		return object, nil
+	case '[':
+		p.pos++
+		list := []interface{}{}
+		for !p.consume(']') {
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+			p.consume(',')
+		}
+		return list, nil
+	}
+
+	// a JSON scalar ends at the next delimiter outside of a string
+	start := p.pos
+	inString := false
+	for ; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		if inString {
+			if c == '\\' {
+				p.pos++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			continue
+		}
+		if c == ',' || c == ':' || c == '}' || c == ']' || c == ')' || c == ' ' || c == '\n' || c == '\t' || c == '\r' {
+			break
+		}
+	}
+
+	token := p.s[start:p.pos]
+	if token != "true" && token != "false" && token != "null" && isIdent(token) {
+		// enum values in hand-written queries are not quoted
+		return token, nil
+	}
+
+	d := json.NewDecoder(bytes.NewBufferString(token))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid value %q at %d: %w", token, start, err)
+	}
+	return v, nil
+}
+
+func (p *parser) ident() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && isIdentChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func isIdent(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *parser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) consume(c byte) bool {
+	if p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(c byte) error {
+	if !p.consume(c) {
+		return fmt.Errorf("expected %q at %d", c, p.pos)
+	}
+	return nil
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\n' || p.s[p.pos] == '\t' || p.s[p.pos] == '\r') {
+		p.pos++
+	}
+}
@@ -0,0 +1,398 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+)
+
+type model struct {
+	name   string
+	fields []*field
+	// uniques contains the fields of every unique constraint, including the primary key
+	uniques [][]string
+	// id contains the fields of the primary key
+	id []string
+}
+
+type field struct {
+	name     string
+	typ      string
+	list     bool
+	optional bool
+	// relation is true if the type is a model
+	relation bool
+	enum     bool
+
+	def       *defaultValue
+	updatedAt bool
+
+	// relationName, relationFields and references are taken from the @relation attribute
+	relationName   string
+	relationFields []string
+	references     []string
+	onDelete       string
+}
+
+type defaultValue struct {
+	// fn is the name of a function such as cuid, uuid, now or autoincrement, or empty for literal values
+	fn    string
+	value interface{}
+}
+
+func (m *model) field(name string) *field {
+	for _, f := range m.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+type schema struct {
+	models map[string]*model
+	enums  map[string][]string
+}
+
+// parseSchema parses the models and enums of a Prisma schema
+func parseSchema(src string) (*schema, error) {
+	s := &schema{
+		models: map[string]*model{},
+		enums:  map[string][]string{},
+	}
+
+	lines := strings.Split(src, "\n")
+	for i := 0; i < len(lines); i++ {
+		words := strings.Fields(stripComment(lines[i]))
+		if len(words) < 3 || words[2] != "{" || (words[0] != "model" && words[0] != "enum") {
+			continue
+		}
+		kind, name := words[0], words[1]
+
+		var body []string
+		for i++; i < len(lines) && strings.TrimSpace(stripComment(lines[i])) != "}"; i++ {
+			if line := strings.TrimSpace(stripComment(lines[i])); line != "" {
+				body = append(body, line)
+			}
+		}
+
+		if kind == "enum" {
+			for _, line := range body {
+				if !strings.HasPrefix(line, "@@") {
+					s.enums[name] = append(s.enums[name], strings.Fields(line)[0])
+				}
+			}
+			continue
+		}
+
+		m := &model{name: name}
+		for _, line := range body {
+			if err := m.parseLine(line); err != nil {
+				return nil, fmt.Errorf("model %s: %w", name, err)
+			}
+		}
+		s.models[name] = m
+	}
+
+	for _, m := range s.models {
+		for _, f := range m.fields {
+			if _, ok := s.models[f.typ]; ok {
+				f.relation = true
+			}
+			if _, ok := s.enums[f.typ]; ok {
+				f.enum = true
+			}
+		}
+		if m.id == nil {
+			return nil, fmt.Errorf("model %s has no @id or @@id", m.name)
+		}
+	}
+
+	return s, nil
+}
+
+func (m *model) parseLine(line string) error {
+	if strings.HasPrefix(line, "@@") {
+		name, args := splitAttribute(line[2:])
+		switch name {
+		case "id":
+			m.id = listArg(args, "fields")
+			m.uniques = append(m.uniques, m.id)
+		case "unique":
+			m.uniques = append(m.uniques, listArg(args, "fields"))
+		}
+		return nil
+	}
+
+	words := strings.Fields(line)
+	if len(words) < 2 {
+		return fmt.Errorf("invalid field %q", line)
+	}
+	f := &field{name: words[0], typ: words[1]}
+	if strings.HasSuffix(f.typ, "?") {
+		f.optional = true
+		f.typ = strings.TrimSuffix(f.typ, "?")
+	}
+	if strings.HasSuffix(f.typ, "[]") {
+		f.list = true
+		f.typ = strings.TrimSuffix(f.typ, "[]")
+	}
+
+	var attrs []string
+	if i := strings.Index(line, "@"); i != -1 {
+		attrs = attributes(line[i:])
+	}
+	for _, attr := range attrs {
+		name, args := splitAttribute(attr)
+		switch name {
+		case "id":
+			m.id = []string{f.name}
+			m.uniques = append(m.uniques, m.id)
+		case "unique":
+			m.uniques = append(m.uniques, []string{f.name})
+		case "updatedAt":
+			f.updatedAt = true
+		case "default":
+			if args := splitArgs(args); len(args) > 0 {
+				f.def = parseDefault(args[0])
+			}
+		case "relation":
+			for i, arg := range splitArgs(args) {
+				key, value := splitNamedArg(arg)
+				switch {
+				case key == "name" || (key == "" && i == 0):
+					f.relationName = strings.Trim(value, `"`)
+				case key == "fields":
+					f.relationFields = parseList(value)
+				case key == "references":
+					f.references = parseList(value)
+				case key == "onDelete":
+					f.onDelete = value
+				}
+			}
+		}
+	}
+
+	m.fields = append(m.fields, f)
+	return nil
+}
+
+// attributes splits the attributes of a field, e.g. `@id @default(cuid())` into `id` and `default(cuid())`
+func attributes(s string) []string {
+	var result []string
+	depth := 0
+	inString := false
+	start := -1
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == '@' && depth == 0:
+			if start >= 0 {
+				result = append(result, strings.TrimSpace(s[start:i]))
+			}
+			start = i + 1
+		}
+	}
+	if start >= 0 {
+		result = append(result, strings.TrimSpace(s[start:]))
+	}
+	return result
+}
+
+// splitAttribute splits `default(cuid())` into `default` and `cuid()`
+func splitAttribute(attr string) (string, string) {
+	i := strings.Index(attr, "(")
+	if i == -1 {
+		return strings.TrimSpace(attr), ""
+	}
+	return attr[:i], strings.TrimSuffix(strings.TrimSpace(attr[i+1:]), ")")
+}
+
+// splitArgs splits attribute arguments on top-level commas
+func splitArgs(s string) []string {
+	var result []string
+	depth := 0
+	inString := false
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == ',' && depth == 0:
+			result = append(result, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if rest := strings.TrimSpace(s[start:]); rest != "" {
+		result = append(result, rest)
+	}
+	return result
+}
+
+// splitNamedArg splits `fields: [a]` into `fields` and `[a]`; unnamed arguments return an empty name
+func splitNamedArg(arg string) (string, string) {
+	if strings.HasPrefix(arg, `"`) || strings.HasPrefix(arg, "[") {
+		return "", arg
+	}
+	i := strings.Index(arg, ":")
+	if i == -1 {
+		return "", arg
+	}
+	return strings.TrimSpace(arg[:i]), strings.TrimSpace(arg[i+1:])
+}
+
+// listArg returns the list of the named or first argument, e.g. `[a, b]` of `fields: [a, b], name: "x"`
+func listArg(args string, name string) []string {
+	for i, arg := range splitArgs(args) {
+		key, value := splitNamedArg(arg)
+		if key == name || (key == "" && i == 0) {
+			return parseList(value)
+		}
+	}
+	return nil
+}
+
+// parseList parses `[a, b(sort: Desc)]` into `a` and `b`
+func parseList(s string) []string {
+	var result []string
+	for _, item := range splitArgs(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "["), "]")) {
+		name, _ := splitAttribute(item)
+		result = append(result, name)
+	}
+	return result
+}
+
+func parseDefault(arg string) *defaultValue {
+	arg = strings.TrimSpace(arg)
+	if name, _ := splitAttribute(arg); strings.HasSuffix(arg, ")") {
+		return &defaultValue{fn: name}
+	}
+	switch {
+	case arg == "true":
+		return &defaultValue{value: true}
+	case arg == "false":
+		return &defaultValue{value: false}
+	case strings.HasPrefix(arg, `"`):
+		return &defaultValue{value: strings.Trim(arg, `"`)}
+	case strings.HasPrefix(arg, "["):
+		var values []interface{}
+		for _, item := range splitArgs(strings.Trim(arg, "[]")) {
+			values = append(values, parseDefault(item).value)
+		}
+		return &defaultValue{value: values}
+	}
+	// numbers are converted to the type of the field later; other values are enum values
+	return &defaultValue{value: literal(arg)}
+}
+
+// literal is a number or an enum value of a default attribute
+type literal string
+
+func stripComment(line string) string {
+	inString := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inString && line[i] == '\\':
+			i++
+		case line[i] == '"':
+			inString = !inString
+		case !inString && strings.HasPrefix(line[i:], "//"):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// relation describes how the records of a relation field are joined
+type relation struct {
+	field *field
+	from  *model
+	to    *model
+
+	// fromFields and toFields join records of both models: from[fromFields[i]] == to[toFields[i]]
+	fromFields []string
+	toFields   []string
+
+	// owner is true if the from model holds the foreign key, i.e. the relation field has the @relation fields
+	owner bool
+
+	// manyToMany relations are stored in a join table instead of foreign keys
+	manyToMany bool
+	// key is the key of the join table of a many to many relation
+	key string
+	// first is true if from is the first model of the join table
+	first bool
+}
+
+// relation returns the relation of the given relation field of a model
+func (s *schema) relation(m *model, f *field) (*relation, error) {
+	to := s.models[f.typ]
+	r := &relation{field: f, from: m, to: to}
+
+	if f.relationFields != nil {
+		r.owner = true
+		r.fromFields = f.relationFields
+		r.toFields = f.references
+		return r, nil
+	}
+
+	back := s.backRelation(m, f)
+	if back == nil {
+		return nil, fmt.Errorf("could not find the opposite relation field of %s.%s", m.name, f.name)
+	}
+	if back.relationFields != nil {
+		r.fromFields = back.references
+		r.toFields = back.relationFields
+		return r, nil
+	}
+
+	if !f.list || !back.list {
+		return nil, fmt.Errorf("relation %s.%s needs @relation fields and references on one side", m.name, f.name)
+	}
+	r.manyToMany = true
+	r.first = m.name < to.name || (m.name == to.name && f.name < back.name)
+	names := []string{m.name, to.name}
+	if !r.first {
+		names = []string{to.name, m.name}
+	}
+	r.key = f.relationName
+	if r.key == "" {
+		r.key = names[0] + "To" + names[1]
+	}
+	r.fromFields = m.id
+	r.toFields = to.id
+	return r, nil
+}
+
+// backRelation returns the relation field on the other side of a relation
+func (s *schema) backRelation(m *model, f *field) *field {
+	for _, other := range s.models[f.typ].fields {
+		if other == f || !other.relation || other.typ != m.name || other.relationName != f.relationName {
+			continue
+		}
+		return other
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// record is a row of a table; it only contains scalar fields
+type record map[string]interface{}
+
+func (r record) clone() record {
+	c := make(record, len(r))
+	for k, v := range r {
+		c[k] = v
+	}
+	return c
+}
+
+// coerce converts a parsed query value to the stored representation of a scalar field:
+// int64 for Int and BigInt, float64 for Float and Decimal, time.Time for DateTime, and the parsed value otherwise
+func coerce(f *field, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if f.list {
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a list for %s", f.name)
+		}
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			c, err := coerceScalar(f.typ, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f.name, err)
+			}
+			result[i] = c
+		}
+		return result, nil
+	}
+	c, err := coerceScalar(f.typ, v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.name, err)
+	}
+	return c, nil
+}
+
+func coerceScalar(typ string, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch typ {
+	case "Int", "BigInt":
+		switch n := v.(type) {
+		case json.Number:
+			if i, err := n.Int64(); err == nil {
+				return i, nil
+			}
+			f, err := n.Float64()
+			if err != nil {
+				return nil, err
+			}
+			return int64(f), nil
+		case string:
+			return strconv.ParseInt(n, 10, 64)
+		case literal:
+			return strconv.ParseInt(string(n), 10, 64)
+		}
+	case "Float", "Decimal":
+		switch n := v.(type) {
+		case json.Number:
+			return n.Float64()
+		case string:
+			return strconv.ParseFloat(n, 64)
+		case literal:
+			return strconv.ParseFloat(string(n), 64)
+		}
+	case "DateTime":
+		if s, ok := v.(string); ok {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+	case "Json":
+		return v, nil
+	default:
+		if l, ok := v.(literal); ok {
+			return string(l), nil
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("invalid %s value %v", typ, v)
+}
+
+// outputValue converts a stored value to the representation of the query engine
+func outputValue(f *field, v interface{}) interface{} {
+	if f.list {
+		items := toList(v)
+		result := make([]interface{}, len(items))
+		for i, item := range items {
+			result[i] = outputValue(&field{typ: f.typ}, item)
+		}
+		return result
+	}
+	// BigInt values are strings so they don't lose precision
+	if n, ok := v.(int64); ok && f.typ == "BigInt" {
+		return strconv.FormatInt(n, 10)
+	}
+	return v
+}
+
+// compare returns -1, 0 or 1 if a is less than, equal to or greater than b.
+// Values are expected to be coerced to the same field type.
+func compare(a, b interface{}) int {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return sign(float64(x) - float64(y))
+		case float64:
+			return sign(float64(x) - y)
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return sign(x - float64(y))
+		case float64:
+			return sign(x - y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			switch {
+			case x.Before(y):
+				return -1
+			case x.After(y):
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+	// lists, json and mismatching types are only compared for equality
+	ab, _ := json.Marshal(a)
+	bb, _ := json.Marshal(b)
+	return strings.Compare(string(ab), string(bb))
+}
+
+func equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return compare(a, b) == 0
+}
+
+func sign(f float64) int {
+	switch {
+	case f < 0:
+		return -1
+	case f > 0:
+		return 1
+	}
+	return 0
+}
+
+func toList(v interface{}) []interface{} {
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	if v == nil {
+		return nil
+	}
+	return []interface{}{v}
+}
+
+func toInt(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err
+	case int64:
+		return int(n), nil
+	case int:
+		return n, nil
+	}
+	return 0, fmt.Errorf("expected a number, got %v", v)
+}
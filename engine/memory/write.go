@@ -0,0 +1,878 @@
+package memory
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
+)
+
+// queryError is an error with a Prisma error code, as the query engine would report it
+type queryError struct {
+	gql engine.GQLError
+}
+
+func (e *queryError) Error() string {
+	return e.gql.UserFacingError.Message
+}
+
+func newQueryError(code types.ErrorCode, message string, meta map[string]interface{}) error {
+	if meta == nil {
+		meta = map[string]interface{}{}
+	}
+	return &queryError{
+		gql: engine.GQLError{
+			Message: message,
+			UserFacingError: &engine.UserFacingError{
+				ErrorCode: string(code),
+				Message:   message,
+				Meta:      meta,
+			},
+		},
+	}
+}
+
+// convertError converts errors with a Prisma error code the same way as errors of the query engine
+func convertError(err error) error {
+	var q *queryError
+	if errors.As(err, &q) {
+		return q.gql.Err()
+	}
+	return err
+}
+
+// batchError returns the error of the i-th query of a batch like the query engine reports it
+func batchError(i int, err error) engine.GQLError {
+	var q *queryError
+	if !errors.As(err, &q) {
+		q = &queryError{
+			gql: engine.GQLError{
+				Message:         err.Error(),
+				UserFacingError: &engine.UserFacingError{Message: err.Error(), Meta: map[string]interface{}{}},
+			},
+		}
+	}
+	q.gql.UserFacingError.Meta["batch_request_idx"] = i
+	return q.gql
+}
+
+func notFound(cause string) error {
+	return newQueryError(
+		types.ErrCodeRecordNotFound,
+		"An operation failed because it depends on one or more records that were required but not found. "+cause,
+		map[string]interface{}{"cause": cause},
+	)
+}
+
+func (e *Engine) write(m *model, op *operation) (interface{}, error) {
+	data := object(op.args["data"])
+
+	switch op.method {
+	case "createOne":
+		r, err := e.create(m, data, nil)
+		if err != nil {
+			return nil, err
+		}
+		return e.render(m, r, op.output)
+	case "createMany":
+		count := 0
+		for _, item := range toList(op.args["data"]) {
+			if _, err := e.create(m, object(item), nil); err != nil {
+				var q *queryError
+				if op.args["skipDuplicates"] == true && errors.As(err, &q) &&
+					q.gql.UserFacingError.ErrorCode == string(types.ErrCodeUniqueConstraint) {
+					continue
+				}
+				return nil, err
+			}
+			count++
+		}
+		return map[string]interface{}{"count": count}, nil
+	case "updateOne":
+		r, err := e.findOne(m, op.args["where"])
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, notFound("Record to update not found.")
+		}
+		if r, err = e.update(m, r, data); err != nil {
+			return nil, err
+		}
+		return e.render(m, r, op.output)
+	case "updateMany":
+		rows, err := e.find(m, e.state.tables[m.name], map[string]interface{}{"where": op.args["where"]})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			if _, err := e.update(m, r, data); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]interface{}{"count": len(rows)}, nil
+	case "deleteOne":
+		r, err := e.findOne(m, op.args["where"])
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, notFound("Record to delete does not exist.")
+		}
+		// the deleted record is returned, so it is rendered before deleting its relations
+		result, err := e.render(m, r, op.output)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.remove(m, r); err != nil {
+			return nil, err
+		}
+		return result, nil
+	case "deleteMany":
+		rows, err := e.find(m, e.state.tables[m.name], map[string]interface{}{"where": op.args["where"]})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			if err := e.remove(m, r); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]interface{}{"count": len(rows)}, nil
+	case "upsertOne":
+		r, err := e.findOne(m, op.args["where"])
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			r, err = e.create(m, object(op.args["create"]), nil)
+		} else {
+			r, err = e.update(m, r, object(op.args["update"]))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return e.render(m, r, op.output)
+	}
+
+	return nil, fmt.Errorf("%s: %w", op.method, ErrUnsupported)
+}
+
+// findOne returns the first record matching a where filter, or nil
+func (e *Engine) findOne(m *model, where interface{}) (record, error) {
+	rows, err := e.find(m, e.state.tables[m.name], map[string]interface{}{"where": where})
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// create inserts a record including nested relation writes. fixed contains values which are set in addition
+// to data, e.g. the foreign key of a record created via its parent.
+func (e *Engine) create(m *model, data map[string]interface{}, fixed record) (record, error) {
+	r := record{}
+	for k, v := range fixed {
+		r[k] = v
+	}
+
+	// scalars and relations holding the foreign key are set before inserting,
+	// all other relations refer to the inserted record
+	var related []string
+	for _, name := range sortedKeys(data) {
+		f := m.field(name)
+		if f == nil {
+			return nil, fmt.Errorf("unknown field %s.%s", m.name, name)
+		}
+		if !f.relation {
+			v := data[name]
+			if o, ok := v.(map[string]interface{}); ok && f.list {
+				v = o["set"]
+			}
+			c, err := coerce(f, v)
+			if err != nil {
+				return nil, err
+			}
+			r[name] = c
+			continue
+		}
+		rel, err := e.schema.relation(m, f)
+		if err != nil {
+			return nil, err
+		}
+		if !rel.owner {
+			related = append(related, name)
+			continue
+		}
+		if err := e.writeOwner(rel, r, object(data[name])); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range m.fields {
+		if f.relation {
+			continue
+		}
+		if v, ok := r[f.name]; ok {
+			if f.def != nil && f.def.fn == "autoincrement" {
+				// explicit values move the sequence like in most databases
+				if n, ok := v.(int64); ok && n > e.state.sequences[m.name+"."+f.name] {
+					e.state.sequences[m.name+"."+f.name] = n
+				}
+			}
+			continue
+		}
+		switch {
+		case f.def != nil:
+			v, err := e.defaultValue(m, f)
+			if err != nil {
+				return nil, err
+			}
+			r[f.name] = v
+		case f.updatedAt:
+			r[f.name] = now()
+		}
+		if r[f.name] == nil && !f.optional && !f.list {
+			return nil, fmt.Errorf("missing a value for the required field %s.%s", m.name, f.name)
+		}
+	}
+
+	if err := e.checkUnique(m, r, nil); err != nil {
+		return nil, err
+	}
+	e.state.tables[m.name] = append(e.state.tables[m.name], r)
+
+	for _, name := range related {
+		rel, err := e.schema.relation(m, m.field(name))
+		if err != nil {
+			return nil, err
+		}
+		if err := e.writeRelated(rel, r, object(data[name])); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// update updates a record including nested relation writes and returns the updated record
+func (e *Engine) update(m *model, old record, data map[string]interface{}) (record, error) {
+	r := old.clone()
+
+	var related []string
+	for _, name := range sortedKeys(data) {
+		f := m.field(name)
+		if f == nil {
+			return nil, fmt.Errorf("unknown field %s.%s", m.name, name)
+		}
+		if !f.relation {
+			v, err := updateScalar(f, r[name], data[name])
+			if err != nil {
+				return nil, err
+			}
+			r[name] = v
+			continue
+		}
+		rel, err := e.schema.relation(m, f)
+		if err != nil {
+			return nil, err
+		}
+		if !rel.owner {
+			related = append(related, name)
+			continue
+		}
+		if err := e.writeOwner(rel, r, object(data[name])); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range m.fields {
+		if _, ok := data[f.name]; f.updatedAt && !ok {
+			r[f.name] = now()
+		}
+		if !f.relation && !f.optional && !f.list && r[f.name] == nil {
+			return nil, newQueryError(
+				types.ErrCodeNullConstraint,
+				fmt.Sprintf("Null constraint violation on the fields: (`%s`)", f.name),
+				map[string]interface{}{"constraint": []interface{}{f.name}},
+			)
+		}
+	}
+
+	if err := e.replace(m, old, r); err != nil {
+		return nil, err
+	}
+
+	for _, name := range related {
+		rel, err := e.schema.relation(m, m.field(name))
+		if err != nil {
+			return nil, err
+		}
+		if err := e.writeRelated(rel, r, object(data[name])); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// updateScalar applies an update operation such as `{set:1}` or `{increment:1}` to a value
+func updateScalar(f *field, current interface{}, v interface{}) (interface{}, error) {
+	ops, ok := v.(map[string]interface{})
+	if !ok || !isUpdateOperation(ops) {
+		return coerce(f, v)
+	}
+
+	scalar := *f
+	scalar.list = false
+	for _, op := range sortedKeys(ops) {
+		operand := ops[op]
+		switch op {
+		case "set":
+			c, err := coerce(f, operand)
+			if err != nil {
+				return nil, err
+			}
+			current = c
+		case "unset":
+			if operand == true {
+				current = nil
+			}
+		case "push":
+			items := append([]interface{}(nil), toList(current)...)
+			for _, item := range toList(operand) {
+				c, err := coerce(&scalar, item)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, c)
+			}
+			current = items
+		case "increment", "decrement", "multiply", "divide":
+			n, err := coerce(&scalar, operand)
+			if err != nil {
+				return nil, err
+			}
+			if current, err = arithmetic(op, current, n); err != nil {
+				return nil, fmt.Errorf("%s: %w", f.name, err)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported update operation %s on %s", op, f.name)
+		}
+	}
+	return current, nil
+}
+
+func isUpdateOperation(ops map[string]interface{}) bool {
+	for op := range ops {
+		switch op {
+		case "set", "unset", "push", "increment", "decrement", "multiply", "divide":
+			return true
+		}
+	}
+	return false
+}
+
+func arithmetic(op string, a, b interface{}) (interface{}, error) {
+	// like in SQL, operations on NULL result in NULL
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	if x, ok := a.(int64); ok {
+		y, ok := b.(int64)
+		if !ok {
+			return nil, fmt.Errorf("invalid operand %v", b)
+		}
+		switch op {
+		case "increment":
+			return x + y, nil
+		case "decrement":
+			return x - y, nil
+		case "multiply":
+			return x * y, nil
+		}
+		if y == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return x / y, nil
+	}
+	x, ok := a.(float64)
+	y, ok2 := b.(float64)
+	if !ok || !ok2 {
+		return nil, fmt.Errorf("invalid operands %v and %v", a, b)
+	}
+	switch op {
+	case "increment":
+		return x + y, nil
+	case "decrement":
+		return x - y, nil
+	case "multiply":
+		return x * y, nil
+	}
+	if y == 0 {
+		return nil, fmt.Errorf("division by zero")
+	}
+	return x / y, nil
+}
+
+// writeOwner runs nested writes of a relation whose foreign key is stored in r, which is not stored yet
+func (e *Engine) writeOwner(rel *relation, r record, ops map[string]interface{}) error {
+	for _, op := range sortedKeys(ops) {
+		arg := ops[op]
+		switch op {
+		case "connect", "create", "connectOrCreate":
+			target, err := e.target(rel, op, arg, nil)
+			if err != nil {
+				return err
+			}
+			for i, f := range rel.fromFields {
+				r[f] = target[rel.toFields[i]]
+			}
+		case "disconnect", "delete":
+			if arg == false {
+				continue
+			}
+			related := e.related(rel, r)
+			if len(related) == 0 {
+				if op == "delete" {
+					return notFound(fmt.Sprintf("No '%s' record was found for a nested delete on relation '%s'.", rel.to.name, rel.field.name))
+				}
+				continue
+			}
+			if err := e.unsetForeignKey(rel.from, rel.fromFields, r); err != nil {
+				return err
+			}
+			if op == "delete" {
+				if err := e.remove(rel.to, related[0]); err != nil {
+					return err
+				}
+			}
+		case "update", "upsert":
+			related := e.related(rel, r)
+			if len(related) > 0 {
+				data := object(arg)
+				if op == "upsert" {
+					data = object(data["update"])
+				}
+				if _, err := e.update(rel.to, related[0], data); err != nil {
+					return err
+				}
+				continue
+			}
+			if op == "update" {
+				return notFound(fmt.Sprintf("No '%s' record was found for a nested update on relation '%s'.", rel.to.name, rel.field.name))
+			}
+			target, err := e.create(rel.to, object(object(arg)["create"]), nil)
+			if err != nil {
+				return err
+			}
+			for i, f := range rel.fromFields {
+				r[f] = target[rel.toFields[i]]
+			}
+		default:
+			return fmt.Errorf("unsupported nested write %s on %s.%s", op, rel.from.name, rel.field.name)
+		}
+	}
+	return nil
+}
+
+// writeRelated runs nested writes of a relation whose records refer to r, either via a foreign key or a join table
+func (e *Engine) writeRelated(rel *relation, r record, ops map[string]interface{}) error {
+	// the foreign key values of records created for r
+	var fixed record
+	if !rel.manyToMany {
+		fixed = record{}
+		for i, f := range rel.toFields {
+			fixed[f] = r[rel.fromFields[i]]
+		}
+	}
+
+	for _, op := range sortedKeys(ops) {
+		arg := ops[op]
+		items := toList(arg)
+		if op == "createMany" {
+			items = toList(object(arg)["data"])
+		}
+
+		switch op {
+		case "set":
+			for _, rr := range e.related(rel, r) {
+				if err := e.unlink(rel, r, rr); err != nil {
+					return err
+				}
+			}
+			fallthrough
+		case "connect", "create", "createMany", "connectOrCreate":
+			action := op
+			switch op {
+			case "set":
+				action = "connect"
+			case "createMany":
+				action = "create"
+			}
+			for _, item := range items {
+				if !rel.field.list {
+					// a to-one relation replaces the currently related record
+					for _, rr := range e.related(rel, r) {
+						if err := e.unlink(rel, r, rr); err != nil {
+							return err
+						}
+					}
+				}
+				target, err := e.target(rel, action, item, fixed)
+				if err != nil {
+					return err
+				}
+				if err := e.link(rel, r, target); err != nil {
+					return err
+				}
+			}
+		case "disconnect", "delete", "deleteMany":
+			for _, item := range items {
+				if item == false {
+					continue
+				}
+				matching, err := e.relatedMatching(rel, r, item)
+				if err != nil {
+					return err
+				}
+				if op == "delete" && len(matching) == 0 {
+					return notFound(fmt.Sprintf("No '%s' record was found for a nested delete on relation '%s'.", rel.to.name, rel.field.name))
+				}
+				for _, rr := range matching {
+					if op == "disconnect" {
+						err = e.unlink(rel, r, rr)
+					} else {
+						err = e.remove(rel.to, rr)
+					}
+					if err != nil {
+						return err
+					}
+				}
+			}
+		case "update", "updateMany", "upsert":
+			for _, item := range items {
+				args := object(item)
+				where, data := args["where"], object(args["data"])
+				if !rel.field.list {
+					// to-one relations don't have a where argument
+					where, data = true, args
+				}
+				if op == "upsert" {
+					data = object(args["update"])
+				}
+				matching, err := e.relatedMatching(rel, r, where)
+				if err != nil {
+					return err
+				}
+				if len(matching) == 0 {
+					switch op {
+					case "update":
+						return notFound(fmt.Sprintf("No '%s' record was found for a nested update on relation '%s'.", rel.to.name, rel.field.name))
+					case "upsert":
+						target, err := e.target(rel, "create", args["create"], fixed)
+						if err != nil {
+							return err
+						}
+						if err := e.link(rel, r, target); err != nil {
+							return err
+						}
+					}
+					continue
+				}
+				for _, rr := range matching {
+					if _, err := e.update(rel.to, rr, data); err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			return fmt.Errorf("unsupported nested write %s on %s.%s", op, rel.from.name, rel.field.name)
+		}
+	}
+	return nil
+}
+
+// relatedMatching returns the related records of r matching a where filter; true matches all
+func (e *Engine) relatedMatching(rel *relation, r record, where interface{}) ([]record, error) {
+	related := e.related(rel, r)
+	if where == true {
+		return related, nil
+	}
+	return e.find(rel.to, related, map[string]interface{}{"where": where})
+}
+
+// target returns the record to connect for a connect, create or connectOrCreate operation
+func (e *Engine) target(rel *relation, op string, arg interface{}, fixed record) (record, error) {
+	switch op {
+	case "connect":
+		target, err := e.findOne(rel.to, arg)
+		if err != nil {
+			return nil, err
+		}
+		if target == nil {
+			return nil, notFound(fmt.Sprintf("No '%s' record was found for a nested connect on relation '%s'.", rel.to.name, rel.field.name))
+		}
+		return target, nil
+	case "connectOrCreate":
+		args := object(arg)
+		target, err := e.findOne(rel.to, args["where"])
+		if err != nil || target != nil {
+			return target, err
+		}
+		return e.create(rel.to, object(args["create"]), fixed)
+	}
+	return e.create(rel.to, object(arg), fixed)
+}
+
+// link connects a record with a record of a relation which refers to r
+func (e *Engine) link(rel *relation, r record, target record) error {
+	if rel.manyToMany {
+		pair := joinPair(rel, r, target)
+		for _, p := range e.state.joins[rel.key] {
+			if p == pair {
+				return nil
+			}
+		}
+		e.state.joins[rel.key] = append(e.state.joins[rel.key], pair)
+		return nil
+	}
+
+	updated := target.clone()
+	for i, f := range rel.toFields {
+		updated[f] = r[rel.fromFields[i]]
+	}
+	return e.replace(rel.to, target, updated)
+}
+
+// unlink disconnects a record from a record of a relation which refers to r
+func (e *Engine) unlink(rel *relation, r record, target record) error {
+	if rel.manyToMany {
+		pair := joinPair(rel, r, target)
+		var pairs [][2]string
+		for _, p := range e.state.joins[rel.key] {
+			if p != pair {
+				pairs = append(pairs, p)
+			}
+		}
+		e.state.joins[rel.key] = pairs
+		return nil
+	}
+
+	updated := target.clone()
+	if err := e.unsetForeignKey(rel.to, rel.toFields, updated); err != nil {
+		return err
+	}
+	return e.replace(rel.to, target, updated)
+}
+
+func joinPair(rel *relation, r record, target record) [2]string {
+	if rel.first {
+		return [2]string{key(r, rel.fromFields), key(target, rel.toFields)}
+	}
+	return [2]string{key(target, rel.toFields), key(r, rel.fromFields)}
+}
+
+// unsetForeignKey sets the foreign key fields of a record to null, which fails for required relations
+func (e *Engine) unsetForeignKey(m *model, fields []string, r record) error {
+	for _, name := range fields {
+		if !m.field(name).optional {
+			return newQueryError(
+				types.ErrCodeRelationViolation,
+				fmt.Sprintf("The change you are trying to make would violate the required relation on the field %s.%s.", m.name, name),
+				map[string]interface{}{"model_name": m.name, "field_name": name},
+			)
+		}
+		r[name] = nil
+	}
+	return nil
+}
+
+// remove deletes a record and applies the referential actions of relations referring to it
+func (e *Engine) remove(m *model, r record) error {
+	i := e.index(m, r)
+	if i == -1 {
+		// already deleted by a cascade
+		return nil
+	}
+	rows := e.state.tables[m.name]
+	e.state.tables[m.name] = append(append([]record(nil), rows[:i]...), rows[i+1:]...)
+
+	for _, name := range sortedModels(e.schema.models) {
+		other := e.schema.models[name]
+		for _, f := range other.fields {
+			if !f.relation || f.typ != m.name || f.relationFields == nil {
+				continue
+			}
+			for _, child := range append([]record(nil), e.state.tables[other.name]...) {
+				if !joined(child, f.relationFields, r, f.references) {
+					continue
+				}
+				onDelete := f.onDelete
+				if onDelete == "" {
+					// the default is SetNull for optional relations and Restrict for required ones
+					onDelete = "Restrict"
+					if other.field(f.relationFields[0]).optional {
+						onDelete = "SetNull"
+					}
+				}
+				switch onDelete {
+				case "Cascade":
+					if err := e.remove(other, child); err != nil {
+						return err
+					}
+				case "SetNull":
+					updated := child.clone()
+					if err := e.unsetForeignKey(other, f.relationFields, updated); err != nil {
+						return err
+					}
+					if err := e.replace(other, child, updated); err != nil {
+						return err
+					}
+				default:
+					return newQueryError(
+						types.ErrCodeForeignKeyConstraint,
+						fmt.Sprintf("Foreign key constraint failed on the field: `%s`", strings.Join(f.relationFields, ", ")),
+						map[string]interface{}{"field_name": strings.Join(f.relationFields, ", ")},
+					)
+				}
+			}
+		}
+	}
+
+	for _, f := range m.fields {
+		if !f.relation || !f.list {
+			continue
+		}
+		rel, err := e.schema.relation(m, f)
+		if err != nil {
+			return err
+		}
+		if !rel.manyToMany {
+			continue
+		}
+		for _, target := range e.related(rel, r) {
+			if err := e.unlink(rel, r, target); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// index returns the position of a record in its table by its primary key, or -1
+func (e *Engine) index(m *model, r record) int {
+	id := key(r, m.id)
+	for i, rr := range e.state.tables[m.name] {
+		if key(rr, m.id) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// replace stores the updated version of a record. Records are never modified in place,
+// so copies of the state still contain the previous versions.
+func (e *Engine) replace(m *model, old record, updated record) error {
+	i := e.index(m, old)
+	if i == -1 {
+		return notFound("Record to update not found.")
+	}
+	if err := e.checkUnique(m, updated, old); err != nil {
+		return err
+	}
+	e.state.tables[m.name][i] = updated
+	return nil
+}
+
+// checkUnique returns an error if a record violates a unique constraint. exclude is the previous version of r, if any.
+func (e *Engine) checkUnique(m *model, r record, exclude record) error {
+	var excluded string
+	if exclude != nil {
+		excluded = key(exclude, m.id)
+	}
+	for _, fields := range m.uniques {
+		// like in SQL, NULL values are never equal
+		null := false
+		for _, f := range fields {
+			if r[f] == nil {
+				null = true
+			}
+		}
+		if null {
+			continue
+		}
+		for _, other := range e.state.tables[m.name] {
+			if exclude != nil && key(other, m.id) == excluded {
+				continue
+			}
+			if joined(r, fields, other, fields) {
+				target := make([]interface{}, len(fields))
+				for i, f := range fields {
+					target[i] = f
+				}
+				return newQueryError(
+					types.ErrCodeUniqueConstraint,
+					fmt.Sprintf("Unique constraint failed on the fields: (`%s`)", strings.Join(fields, "`,`")),
+					map[string]interface{}{"target": target},
+				)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *Engine) defaultValue(m *model, f *field) (interface{}, error) {
+	switch f.def.fn {
+	case "":
+		return coerce(f, f.def.value)
+	case "autoincrement":
+		name := m.name + "." + f.name
+		e.state.sequences[name]++
+		return e.state.sequences[name], nil
+	case "now":
+		return now(), nil
+	case "cuid":
+		return "c" + randomHex(12), nil
+	case "uuid":
+		b := randomHex(16)
+		return b[:8] + "-" + b[8:12] + "-4" + b[13:16] + "-a" + b[17:20] + "-" + b[20:], nil
+	}
+	// database generated values such as dbgenerated() are not known
+	return nil, nil
+}
+
+// now returns the current time with the millisecond precision of the query engine
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// sortedKeys returns the keys of an object in a deterministic order
+func sortedKeys(o map[string]interface{}) []string {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedModels(models map[string]*model) []string {
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
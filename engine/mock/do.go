@@ -3,49 +3,287 @@ package mock
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
 )
 
+// ErrUnexpectedQuery is returned for queries which don't match any expectation. The mismatch, including a diff
+// to the closest expectation, is reported via the test passed to NewEngine, or else by Mock.Ensure.
+var ErrUnexpectedQuery = errors.New("unexpected query")
+
 func (e *Engine) Do(ctx context.Context, payload interface{}, v interface{}) error {
 	e.expMu.Lock()
 	defer e.expMu.Unlock()
 
-	expectations := *e.expectations
-
-	n := -1
-	for i, e := range expectations {
-		req := payload.(engine.GQLRequest)
-		if e.Query.Build() == req.Query {
-			n = i
-			break
-		}
+	req := payload.(engine.GQLRequest)
+	var query *builder.Query
+	if r := builder.RequestFromContext(ctx); r != nil {
+		query = r.Query
 	}
-	if n == -1 {
-		panic("could not find query")
+
+	exp, err := e.match(req.Query, query)
+	if err != nil {
+		return err
 	}
-	var retErr error
+	exp.call()
+
 	switch {
-	case expectations[n].Want != nil:
-		r, err := json.Marshal(expectations[n].Want)
+	case exp.Want != nil:
+		r, err := json.Marshal(exp.Want)
 		if err != nil {
 			return fmt.Errorf("error happened at unmarshaling expectation want: %w", err)
 		}
 		if err := json.Unmarshal(r, &v); err != nil {
 			return fmt.Errorf("error happened at marshaling expectation want: %w", err)
 		}
-	case expectations[n].WantErr != nil:
-		retErr = expectations[n].WantErr
+		return nil
+	case exp.WantErr != nil:
+		return exp.WantErr
 	default:
 		panic("need to define either Want or WantErr")
 	}
-	expectations[n].Success = true
-	*e.expectations = expectations
-	return retErr
 }
 
+// Batch answers a transaction which was expected via ExpectTransaction. The result of each query is taken from
+// the expectation of that query. The transaction stops at the first query which is expected to fail, and its
+// error is reported with the index of the query like the query engine does.
 func (e *Engine) Batch(ctx context.Context, payload interface{}, v interface{}) error {
-	// TODO
-	panic("TODO")
+	e.expMu.Lock()
+	defer e.expMu.Unlock()
+
+	req := payload.(engine.GQLBatchRequest)
+	queries := make([]string, len(req.Batch))
+	for i, q := range req.Batch {
+		queries[i] = q.Query
+	}
+	var batch []*builder.Query
+	if r := builder.RequestFromContext(ctx); r != nil && len(r.Batch) == len(queries) {
+		batch = r.Batch
+	}
+
+	tx, err := e.matchTransaction(queries, batch)
+	if err != nil {
+		return err
+	}
+	tx.call()
+
+	var response engine.GQLBatchResponse
+	for i, query := range queries {
+		var q *builder.Query
+		if batch != nil {
+			q = batch[i]
+		}
+		exp, err := e.match(query, q)
+		if err != nil {
+			return err
+		}
+		exp.call()
+
+		switch {
+		case exp.Want != nil:
+			r, err := json.Marshal(exp.Want)
+			if err != nil {
+				return fmt.Errorf("error happened at marshaling expectation want: %w", err)
+			}
+			var result engine.GQLResponse
+			result.Data.Result = r
+			response.Result = append(response.Result, result)
+		case exp.WantErr != nil:
+			response = engine.GQLBatchResponse{Errors: []engine.GQLError{batchError(i, exp.WantErr)}}
+		default:
+			panic("need to define either Want or WantErr")
+		}
+		if len(response.Errors) > 0 {
+			break
+		}
+	}
+
+	r, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("error happened at marshaling batch response: %w", err)
+	}
+	if err := json.Unmarshal(r, &v); err != nil {
+		return fmt.Errorf("error happened at unmarshaling batch response: %w", err)
+	}
+	return nil
+}
+
+// batchError returns the error of the i-th query of a batch like the query engine reports it, so the transaction
+// returns a BatchError. Errors with a Prisma error code keep it, e.g. to match ErrNotFound.
+func batchError(i int, err error) engine.GQLError {
+	f := &engine.UserFacingError{
+		Message: err.Error(),
+		Meta:    map[string]interface{}{},
+	}
+	var e *types.Error
+	switch {
+	case errors.As(err, &e):
+		f.ErrorCode = string(e.Code)
+		f.Message = e.Message
+		for k, v := range e.Meta {
+			f.Meta[k] = v
+		}
+	case errors.Is(err, types.ErrNotFound):
+		f.ErrorCode = string(types.ErrCodeRecordNotFound)
+	}
+	f.Meta["batch_request_idx"] = float64(i)
+	return engine.GQLError{
+		Message:         f.Message,
+		UserFacingError: f,
+	}
+}
+
+// match returns the query expectation to answer the query with. The structured query is nil if the query was not
+// sent via builder.Intercept, in which case expectations can only be matched by the built query string.
+func (e *Engine) match(built string, query *builder.Query) (*Expectation, error) {
+	var actual []line
+	if query != nil {
+		actual = flatten(*query)
+	} else {
+		actual = []line{{path: built}}
+	}
+
+	var candidates []*Expectation
+	for _, exp := range e.all() {
+		if exp.Transaction != nil {
+			continue
+		}
+		if exp.Query.Build() == built || (query != nil && len(exp.args) > 0 && equal(apply(flatten(exp.Query), exp.args, false), apply(actual, exp.args, true))) {
+			candidates = append(candidates, exp)
+		}
+	}
+
+	if len(candidates) == 0 {
+		var closest *Expectation
+		var closestDiff string
+		closestChanges := -1
+		for _, exp := range e.all() {
+			if exp.Transaction != nil {
+				continue
+			}
+			expected := []line{{path: exp.Query.Build()}}
+			if query != nil {
+				expected = apply(flatten(exp.Query), exp.args, false)
+			}
+			d, changes := diff(expected, apply(actual, exp.args, true))
+			if closestChanges == -1 || changes < closestChanges {
+				closest, closestDiff, closestChanges = exp, d, changes
+			}
+		}
+		if closest == nil {
+			return nil, e.mismatch("unexpected query `%s`: no query expectations defined", built)
+		}
+		return nil, e.mismatch("unexpected query `%s`, diff to the closest expectation (- expected, + actual):\n%s", built, closestDiff)
+	}
+
+	exp := candidates[0]
+	for _, c := range candidates {
+		if !c.exhausted() {
+			exp = c
+			break
+		}
+	}
+	if exp.exhausted() {
+		return nil, e.mismatch("query `%s` was sent more often than expected: expected %d times", built, exp.times)
+	}
+
+	if err := e.checkOrder(exp, built); err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
+
+// matchTransaction returns the transaction expectation to answer the transaction with
+func (e *Engine) matchTransaction(built []string, queries []*builder.Query) (*Expectation, error) {
+	var actual []builder.Query
+	for _, q := range queries {
+		actual = append(actual, *q)
+	}
+
+	var candidates []*Expectation
+	for _, exp := range e.all() {
+		if exp.Transaction == nil || len(exp.Transaction) != len(built) {
+			continue
+		}
+		match := true
+		for i, q := range exp.Transaction {
+			if q.Build() != built[i] {
+				match = false
+				break
+			}
+		}
+		if !match && actual != nil && len(exp.args) > 0 {
+			match = equal(apply(transactionLines(exp.Transaction), exp.args, false), apply(transactionLines(actual), exp.args, true))
+		}
+		if match {
+			candidates = append(candidates, exp)
+		}
+	}
+
+	if len(candidates) == 0 {
+		var closest string
+		closestChanges := -1
+		if actual != nil {
+			for _, exp := range e.all() {
+				if exp.Transaction == nil {
+					continue
+				}
+				d, changes := diff(apply(transactionLines(exp.Transaction), exp.args, false), apply(transactionLines(actual), exp.args, true))
+				if closestChanges == -1 || changes < closestChanges {
+					closest, closestChanges = d, changes
+				}
+			}
+		}
+		if closestChanges == -1 {
+			return nil, e.mismatch("unexpected transaction with queries %q", built)
+		}
+		return nil, e.mismatch("unexpected transaction with queries %q, diff to the closest expectation (- expected, + actual):\n%s", built, closest)
+	}
+
+	exp := candidates[0]
+	for _, c := range candidates {
+		if !c.exhausted() {
+			exp = c
+			break
+		}
+	}
+	if exp.exhausted() {
+		return nil, e.mismatch("transaction with queries %q was sent more often than expected: expected %d times", built, exp.times)
+	}
+
+	if err := e.checkOrder(exp, fmt.Sprintf("%q", built)); err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
+
+// checkOrder returns an error if exp is expected in order, but a previous expectation in order was not met yet
+func (e *Engine) checkOrder(exp *Expectation, built string) error {
+	if !exp.inOrder {
+		return nil
+	}
+	for _, prev := range e.all() {
+		if prev == exp {
+			return nil
+		}
+		if prev.inOrder && prev.calls < prev.min() {
+			return e.mismatch("query `%s` was sent out of order: expected %s first", built, prev)
+		}
+	}
+	return nil
+}
+
+// mismatch records a query which didn't match any expectation, reports it via the test if the engine has one,
+// and returns an error for it
+func (e *Engine) mismatch(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	e.mismatches = append(e.mismatches, msg)
+	if e.t != nil {
+		e.t.Errorf("%s", msg)
+	}
+	return fmt.Errorf("%w: %s", ErrUnexpectedQuery, msg)
 }
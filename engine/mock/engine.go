@@ -2,17 +2,34 @@ package mock
 
 import (
 	"sync"
+	"testing"
 )
 
+// New returns an engine which answers queries with the given expectations, including the ones appended later.
+// Queries which don't match any expectation are reported by Mock.Ensure.
 func New(expectations *[]Expectation) *Engine {
 	return &Engine{
-		expectations: expectations,
+		values: expectations,
+	}
+}
+
+// NewEngine returns an engine which answers queries with the expectations added via Add. Queries which don't match
+// any expectation are reported via t.Errorf when they are sent; t may be nil to report them only via Mock.Ensure.
+func NewEngine(t testing.TB) *Engine {
+	return &Engine{
+		t: t,
 	}
 }
 
 type Engine struct {
-	expectations *[]Expectation
+	// values contains the expectations passed to New
+	values       *[]Expectation
+	expectations []*Expectation
 	expMu        sync.Mutex
+
+	t testing.TB
+	// mismatches contains a description of each query which didn't match any expectation
+	mismatches []string
 }
 
 func (e *Engine) Name() string {
@@ -26,3 +43,30 @@ func (e *Engine) Connect() error {
 func (e *Engine) Disconnect() error {
 	panic("this is a mock client – you don't need to connect or disconnect this client")
 }
+
+// Add adds an expectation and returns it, so options such as Times can be set
+func (e *Engine) Add(exp *Expectation) *Expectation {
+	e.expMu.Lock()
+	defer e.expMu.Unlock()
+	e.expectations = append(e.expectations, exp)
+	return exp
+}
+
+// all returns all expectations in the order they were added
+func (e *Engine) all() []*Expectation {
+	var all []*Expectation
+	if e.values != nil {
+		for i := range *e.values {
+			all = append(all, &(*e.values)[i])
+		}
+	}
+	return append(all, e.expectations...)
+}
+
+// Mismatches returns a description of each query which was sent but didn't match any expectation,
+// including a diff to the closest expectation
+func (e *Engine) Mismatches() []string {
+	e.expMu.Lock()
+	defer e.expMu.Unlock()
+	return append([]string(nil), e.mismatches...)
+}
@@ -0,0 +1,217 @@
+package mock
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+)
+
+// Matcher matches an argument of a query, see Expectation.Arg
+type Matcher interface {
+	// Match returns whether the value matches. The value is passed as given to the query,
+	// e.g. a string for User.Email.Equals("...").
+	Match(v interface{}) bool
+	String() string
+}
+
+type anyMatcher struct{}
+
+func (anyMatcher) Match(interface{}) bool { return true }
+func (anyMatcher) String() string         { return "<any>" }
+
+// Any matches any value
+func Any() Matcher {
+	return anyMatcher{}
+}
+
+type funcMatcher func(v interface{}) bool
+
+func (f funcMatcher) Match(v interface{}) bool { return f(v) }
+func (funcMatcher) String() string             { return "<match>" }
+
+// Match matches values for which fn returns true
+func Match(fn func(v interface{}) bool) Matcher {
+	return funcMatcher(fn)
+}
+
+type arg struct {
+	path    string
+	matcher Matcher
+}
+
+// line is a single property of a flattened query, e.g. `where.email.equals = "a@b.c"`
+type line struct {
+	path  string
+	value interface{}
+	text  string
+}
+
+func (l line) String() string {
+	if l.text == "" {
+		return l.path
+	}
+	return l.path + " = " + l.text
+}
+
+// flatten returns the properties of a query line by line, so queries can be compared and diffed structurally
+func flatten(q builder.Query) []line {
+	lines := []line{
+		{path: "operation " + q.Operation},
+		{path: "method " + q.Method},
+	}
+	if q.Model != "" {
+		lines = append(lines, line{path: "model " + q.Model})
+	}
+	lines = flattenInputs(lines, "", q.Inputs)
+	lines = flattenOutputs(lines, "select", q.Outputs)
+	return lines
+}
+
+func flattenInputs(lines []line, prefix string, inputs []builder.Input) []line {
+	for _, i := range inputs {
+		path := join(prefix, i.Name)
+		if i.Value != nil || i.Fields == nil {
+			lines = append(lines, valueLine(path, i.Value))
+			continue
+		}
+		lines = flattenFields(lines, path, i.Fields)
+	}
+	return lines
+}
+
+func flattenFields(lines []line, prefix string, fields []builder.Field) []line {
+	if len(fields) == 0 {
+		return append(lines, line{path: prefix, text: "{}"})
+	}
+	item := 0
+	for _, f := range fields {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("[%d]", item)
+			item++
+		}
+		path := join(prefix, name)
+		if f.Value != nil || f.Fields == nil {
+			lines = append(lines, valueLine(path, f.Value))
+			continue
+		}
+		lines = flattenFields(lines, path, f.Fields)
+	}
+	return lines
+}
+
+func flattenOutputs(lines []line, prefix string, outputs []builder.Output) []line {
+	for _, o := range outputs {
+		path := join(prefix, o.Name)
+		if len(o.Inputs) == 0 && len(o.Outputs) == 0 {
+			lines = append(lines, line{path: path})
+			continue
+		}
+		var inputs []line
+		for _, l := range flattenInputs(nil, "", o.Inputs) {
+			l.path = path + "(" + l.path + ")"
+			inputs = append(inputs, l)
+		}
+		lines = append(lines, inputs...)
+		lines = flattenOutputs(lines, path, o.Outputs)
+	}
+	return lines
+}
+
+func valueLine(path string, value interface{}) line {
+	return line{path: path, value: value, text: string(builder.Value(value))}
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// apply replaces the values of the lines at the paths of args with the matcher, so they compare equal to the
+// expected lines regardless of the value. If actual is true, the values are only replaced if they match.
+func apply(lines []line, args []arg, actual bool) []line {
+	if len(args) == 0 {
+		return lines
+	}
+	result := make([]line, len(lines))
+	for i, l := range lines {
+		for _, a := range args {
+			if l.path != a.path {
+				continue
+			}
+			if !actual || a.matcher.Match(l.value) {
+				l.text = a.matcher.String()
+			}
+		}
+		result[i] = l
+	}
+	return result
+}
+
+// transactionLines flattens all queries of a transaction, prefixed by their index
+func transactionLines(queries []builder.Query) []line {
+	var lines []line
+	for i, q := range queries {
+		for _, l := range flatten(q) {
+			l.path = fmt.Sprintf("[%d] %s", i, l.path)
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+func equal(a, b []line) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+// diff returns a line diff from expected to actual and the number of differing lines
+func diff(expected, actual []line) (string, int) {
+	// longest common subsequence of both line lists
+	lcs := make([][]int, len(expected)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expected[i].String() == actual[j].String() {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var b strings.Builder
+	changes := 0
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i].String() == actual[j].String():
+			b.WriteString("  " + expected[i].String() + "\n")
+			i++
+			j++
+		case i < len(expected) && (j == len(actual) || lcs[i+1][j] >= lcs[i][j+1]):
+			b.WriteString("- " + expected[i].String() + "\n")
+			changes++
+			i++
+		default:
+			b.WriteString("+ " + actual[j].String() + "\n")
+			changes++
+			j++
+		}
+	}
+	return b.String(), changes
+}
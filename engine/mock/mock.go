@@ -1,16 +1,92 @@
 package mock
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
 )
 
 type Expectation struct {
-	Query   builder.Query
-	Want    interface{}
-	WantErr error
+	Query builder.Query
+	// Transaction contains the queries of an expected transaction in order, in which case Query is not used.
+	// The results of the queries are defined by their own expectations.
+	Transaction []builder.Query
+	Want        interface{}
+	WantErr     error
+	// Success is true once the expectation was met, i.e. it was called often enough
 	Success bool
+
+	// times is the exact number of expected calls; 0 means at least once
+	times   int
+	maybe   bool
+	inOrder bool
+	args    []arg
+	calls   int
+}
+
+// Times expects the query to be sent exactly n times. By default, a query is expected at least once.
+func (e *Expectation) Times(n int) *Expectation {
+	if n < 1 {
+		panic("mock: Times needs to be called with at least 1, use Maybe for optional queries")
+	}
+	e.times = n
+	return e
+}
+
+// Maybe makes the query optional, so the expectation is met even if the query is never sent
+func (e *Expectation) Maybe() *Expectation {
+	e.maybe = true
+	return e
+}
+
+// InOrder expects the query to be sent only after all previously defined InOrder expectations were met
+func (e *Expectation) InOrder() *Expectation {
+	e.inOrder = true
+	return e
+}
+
+// Arg matches the argument at the given path with m instead of comparing it to the value of the expected query.
+// The path consists of the argument and field names separated by dots, e.g. "where.email.equals" or "data.name",
+// and items of lists are addressed by their index, e.g. "data.posts.create.[0].title".
+// The expected query still needs to contain the argument; its value is ignored.
+func (e *Expectation) Arg(path string, m Matcher) *Expectation {
+	e.args = append(e.args, arg{path: path, matcher: m})
+	return e
+}
+
+// min returns the number of calls needed to meet the expectation
+func (e *Expectation) min() int {
+	switch {
+	case e.maybe:
+		return 0
+	case e.times > 0:
+		return e.times
+	default:
+		return 1
+	}
+}
+
+// exhausted returns whether the expectation was called as often as allowed
+func (e *Expectation) exhausted() bool {
+	return e.times > 0 && e.calls >= e.times
+}
+
+// call records a call of the expectation
+func (e *Expectation) call() {
+	e.calls++
+	e.Success = e.calls >= e.min()
+}
+
+func (e *Expectation) String() string {
+	if e.Transaction != nil {
+		queries := make([]string, len(e.Transaction))
+		for i, q := range e.Transaction {
+			queries[i] = q.Build()
+		}
+		return fmt.Sprintf("transaction with queries `%s`", queries)
+	}
+	return fmt.Sprintf("query `%s` and result `%s`, error `%s`", e.Query.Build(), e.Want, e.WantErr)
 }
 
 type Query interface {
@@ -18,16 +94,57 @@ type Query interface {
 }
 
 type Mock struct {
+	// Expectations contains the expectations of an engine created via New
 	Expectations *[]Expectation
+	Engine       *Engine
+}
+
+// Add adds an expectation to the engine and returns it, so options such as Times can be set
+func (m *Mock) Add(e *Expectation) *Expectation {
+	return m.Engine.Add(e)
 }
 
+// Ensure reports queries which didn't match any expectation and expectations which were not met
 func (m *Mock) Ensure(t *testing.T) {
-	if len(*m.Expectations) == 0 {
+	t.Helper()
+	m.Verify(t)
+}
+
+// Verify is like Ensure, but accepts any testing.TB, e.g. to be called via t.Cleanup
+func (m *Mock) Verify(t testing.TB) {
+	t.Helper()
+
+	var expectations []*Expectation
+	// an engine with a test already reported every unexpected query, so a test may expect that no query is sent
+	strict := true
+	if m.Engine != nil {
+		m.Engine.expMu.Lock()
+		defer m.Engine.expMu.Unlock()
+		expectations = m.Engine.all()
+		strict = m.Engine.t == nil
+		// an engine with a test reports mismatches when they happen
+		if m.Engine.t == nil {
+			for _, mismatch := range m.Engine.mismatches {
+				t.Errorf("%s", mismatch)
+			}
+		}
+	} else if m.Expectations != nil {
+		for i := range *m.Expectations {
+			expectations = append(expectations, &(*m.Expectations)[i])
+		}
+	}
+
+	if len(expectations) == 0 && strict {
 		t.Fatalf("no expectations defined")
 	}
-	for _, e := range *m.Expectations {
-		if !e.Success {
-			t.Fatalf("expectation not met for query `%s` and result `%s`, error `%s`", e.Query.Build(), e.Want, e.WantErr)
+	for _, e := range expectations {
+		if e.calls >= e.min() {
+			continue
+		}
+		if e.calls == 0 {
+			t.Errorf("expectation not met for %s", e)
+			continue
 		}
+		t.Errorf("expectation not met for %s: called %d times, expected %d times", e, e.calls, e.min())
 	}
 }
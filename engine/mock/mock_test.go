@@ -0,0 +1,280 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+	"github.com/vnsoft2014/prisma-client-go/runtime/transaction"
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
+)
+
+func findUnique(e *Engine, id interface{}) builder.Query {
+	q := builder.NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findUnique"
+	q.Model = "User"
+	q.Inputs = []builder.Input{{
+		Name: "where",
+		Fields: []builder.Field{{
+			Name:   "id",
+			Fields: []builder.Field{{Name: "equals", Value: id}},
+		}},
+	}}
+	q.Outputs = []builder.Output{{Name: "id"}, {Name: "name"}}
+	return q
+}
+
+type user struct {
+	ID string `json:"id"`
+}
+
+func setup() (*Engine, *Mock) {
+	e := NewEngine(nil)
+	return e, &Mock{Engine: e}
+}
+
+// recorder records the errors reported via a test
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Helper() {}
+
+func exec(e *Engine, id interface{}) (user, error) {
+	var v user
+	err := findUnique(e, id).Exec(context.Background(), &v)
+	return v, err
+}
+
+func TestExpectation_Times(t *testing.T) {
+	e, m := setup()
+	m.Add(&Expectation{Query: findUnique(e, "a"), Want: user{ID: "a"}}).Times(2)
+
+	for i := 0; i < 2; i++ {
+		if _, err := exec(e, "a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := exec(e, "a"); !errors.Is(err, ErrUnexpectedQuery) {
+		t.Fatalf("expected ErrUnexpectedQuery for the third call, got %v", err)
+	}
+	if mismatches := e.Mismatches(); len(mismatches) != 1 || !strings.Contains(mismatches[0], "expected 2 times") {
+		t.Errorf("unexpected mismatches %q", mismatches)
+	}
+}
+
+func TestExpectation_Maybe(t *testing.T) {
+	e, m := setup()
+	exp := m.Add(&Expectation{Query: findUnique(e, "a"), Want: user{ID: "a"}}).Maybe()
+
+	if exp.calls < exp.min() {
+		t.Errorf("expected an optional expectation to be met without calls")
+	}
+}
+
+func TestExpectation_InOrder(t *testing.T) {
+	e, m := setup()
+	m.Add(&Expectation{Query: findUnique(e, "a"), Want: user{ID: "a"}}).InOrder()
+	m.Add(&Expectation{Query: findUnique(e, "b"), Want: user{ID: "b"}}).InOrder()
+
+	if _, err := exec(e, "b"); !errors.Is(err, ErrUnexpectedQuery) {
+		t.Fatalf("expected ErrUnexpectedQuery for a query out of order, got %v", err)
+	}
+	if _, err := exec(e, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := exec(e, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if mismatches := e.Mismatches(); len(mismatches) != 1 || !strings.Contains(mismatches[0], "out of order") {
+		t.Errorf("unexpected mismatches %q", mismatches)
+	}
+}
+
+func TestExpectation_Arg(t *testing.T) {
+	e, m := setup()
+	m.Add(&Expectation{Query: findUnique(e, ""), Want: user{ID: "any"}}).Arg("where.id.equals", Any())
+	m.Add(&Expectation{Query: findUnique(e, 0), Want: user{ID: "int"}}).Arg("where.id.equals", Match(func(v interface{}) bool {
+		_, ok := v.(int)
+		return ok
+	}))
+
+	v, err := exec(e, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != "any" {
+		t.Errorf("expected the first matching expectation, got %q", v.ID)
+	}
+	if _, err := exec(e, "foo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_Do_diff(t *testing.T) {
+	e, m := setup()
+	m.Add(&Expectation{Query: findUnique(e, "a"), Want: user{ID: "a"}})
+
+	_, err := exec(e, "b")
+	if !errors.Is(err, ErrUnexpectedQuery) {
+		t.Fatalf("expected ErrUnexpectedQuery, got %v", err)
+	}
+
+	expected := "  operation query\n" +
+		"  method findUnique\n" +
+		"  model User\n" +
+		"- where.id.equals = \"a\"\n" +
+		"+ where.id.equals = \"b\"\n" +
+		"  select.id\n" +
+		"  select.name\n"
+	mismatches := e.Mismatches()
+	if len(mismatches) != 1 || !strings.HasSuffix(mismatches[0], expected) {
+		t.Errorf("expected diff\n%s\ngot %q", expected, mismatches)
+	}
+}
+
+func TestNewEngine_reportsMismatches(t *testing.T) {
+	r := &recorder{TB: t}
+	e := NewEngine(r)
+	m := &Mock{Engine: e}
+	m.Add(&Expectation{Query: findUnique(e, "a"), Want: user{ID: "a"}})
+
+	if _, err := exec(e, "b"); !errors.Is(err, ErrUnexpectedQuery) {
+		t.Fatalf("expected ErrUnexpectedQuery, got %v", err)
+	}
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "unexpected query") {
+		t.Fatalf("expected the mismatch to be reported when the query is sent, got %q", r.errors)
+	}
+
+	if _, err := exec(e, "a"); err != nil {
+		t.Fatal(err)
+	}
+	m.Verify(r)
+	if len(r.errors) != 1 {
+		t.Errorf("expected the mismatch to be reported only once, got %q", r.errors)
+	}
+}
+
+func TestNewEngine_noExpectations(t *testing.T) {
+	r := &recorder{TB: t}
+	m := &Mock{Engine: NewEngine(r)}
+
+	// a test may expect that no query is sent at all
+	m.Verify(r)
+	if len(r.errors) != 0 {
+		t.Errorf("unexpected errors %q", r.errors)
+	}
+}
+
+func TestNew(t *testing.T) {
+	expectations := new([]Expectation)
+	e := New(expectations)
+	m := &Mock{Expectations: expectations}
+	*expectations = append(*expectations, Expectation{Query: findUnique(e, "a"), Want: user{ID: "a"}})
+
+	v, err := exec(e, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ID != "a" || !(*expectations)[0].Success {
+		t.Errorf("expected the expectation to be met, got %+v", v)
+	}
+
+	r := &recorder{TB: t}
+	m.Verify(r)
+	if len(r.errors) != 0 {
+		t.Errorf("unexpected errors %q", r.errors)
+	}
+}
+
+type txParam struct {
+	query builder.Query
+}
+
+func (p txParam) IsTx() {}
+
+func (p txParam) ExtractQuery() builder.Query {
+	return p.query
+}
+
+func TestEngine_Batch_error(t *testing.T) {
+	e, m := setup()
+	a := findUnique(e, "a")
+	a.TxResult = make(chan []byte, 1)
+	b := findUnique(e, "b")
+	b.TxResult = make(chan []byte, 1)
+	m.Add(&Expectation{Query: a, Want: user{ID: "a"}})
+	m.Add(&Expectation{Query: b, WantErr: types.ErrNotFound})
+	m.Add(&Expectation{Transaction: []builder.Query{a, b}})
+
+	err := transaction.TX{Engine: e}.Transaction(txParam{a}, txParam{b}).Exec(context.Background())
+
+	var batchErr *transaction.BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 1 {
+		t.Fatalf("expected a BatchError for the second query, got %v", err)
+	}
+	if !errors.Is(err, types.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, ok := types.CheckErrorCode(err, types.ErrCodeRecordNotFound); !ok {
+		t.Errorf("expected error code P2025, got %v", err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+		actual   []string
+		want     string
+		changes  int
+	}{{
+		name:     "equal",
+		expected: []string{"a", "b"},
+		actual:   []string{"a", "b"},
+		want:     "  a\n  b\n",
+	}, {
+		name:     "added",
+		expected: []string{"a", "c"},
+		actual:   []string{"a", "b", "c"},
+		want:     "  a\n+ b\n  c\n",
+		changes:  1,
+	}, {
+		name:     "removed",
+		expected: []string{"a", "b", "c"},
+		actual:   []string{"a", "c"},
+		want:     "  a\n- b\n  c\n",
+		changes:  1,
+	}, {
+		name:     "changed",
+		expected: []string{"a", "b"},
+		actual:   []string{"a", "c"},
+		want:     "  a\n- b\n+ c\n",
+		changes:  2,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := func(s []string) []line {
+				var result []line
+				for _, l := range s {
+					result = append(result, line{path: l})
+				}
+				return result
+			}
+			got, changes := diff(lines(tt.expected), lines(tt.actual))
+			if got != tt.want || changes != tt.changes {
+				t.Errorf("diff() = %q, %d, want %q, %d", got, changes, tt.want, tt.changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/vnsoft2014/prisma-client-go/logger"
+)
+
+type datasourceOverride struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type options struct {
+	datasources    []datasourceOverride
+	binaryPath     string
+	httpClient     *http.Client
+	connectTimeout time.Duration
+	requestTimeout time.Duration
+	restart        *RestartPolicy
+	healthCallback func(HealthEvent)
+	logHandler     slog.Handler
+	logLevel       *slog.Level
+	// logs is built from logHandler once the options are applied; nil uses the default logger
+	logs       *slog.Logger
+	transport  TransportOptions
+	unixSocket bool
+	socketDir  string
+}
+
+// Option configures an engine
+type Option func(*options)
+
+// WithDatasource overrides the connection url of the datasource with the given name.
+// A later override for the same datasource replaces an earlier one.
+func WithDatasource(name string, url string) Option {
+	return func(o *options) {
+		for i, d := range o.datasources {
+			if d.Name == name {
+				o.datasources[i].URL = url
+				return
+			}
+		}
+		o.datasources = append(o.datasources, datasourceOverride{
+			Name: name,
+			URL:  url,
+		})
+	}
+}
+
+// WithBinaryPath sets the path of the query engine binary. It takes precedence over PRISMA_QUERY_ENGINE_BINARY,
+// and the version of the binary is not checked.
+func WithBinaryPath(path string) Option {
+	return func(o *options) {
+		o.binaryPath = path
+	}
+}
+
+// WithHTTPClient sets the http client used to send requests to the engine, instead of a client with a transport
+// tuned via WithTransport
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithTransport tunes the http transport used to send requests to the engine, e.g. the number of idle connections
+// kept open. Zero values use the defaults of DefaultTransportOptions.
+func WithTransport(t TransportOptions) Option {
+	return func(o *options) {
+		o.transport = t
+	}
+}
+
+// WithUnixSocket makes the query engine listen on a unix domain socket in the given directory instead of a tcp port.
+// An empty directory uses the temp dir. It can't be combined with WithHTTPClient.
+func WithUnixSocket(dir string) Option {
+	return func(o *options) {
+		o.unixSocket = true
+		o.socketDir = dir
+	}
+}
+
+// WithConnectTimeout sets the maximum amount of time to wait for the engine to be ready when connecting
+func WithConnectTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.connectTimeout = d
+	}
+}
+
+// WithRequestTimeout sets the maximum amount of time a single request to the engine can take, overriding
+// DefaultRequestTimeout of one minute; zero disables it, e.g. for long-running batch updates. An earlier deadline
+// of the request context still applies.
+func WithRequestTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.requestTimeout = d
+	}
+}
+
+// WithRestart restarts the query engine on a new port when its process exits unexpectedly.
+// Requests sent while the engine is down fail with ErrEngineCrashed.
+func WithRestart(policy RestartPolicy) Option {
+	return func(o *options) {
+		o.restart = &policy
+	}
+}
+
+// WithHealthCallback sets a function which is called when the query engine crashes, is restarted or stays down.
+// The callback is called from a separate goroutine and should not block.
+func WithHealthCallback(fn func(HealthEvent)) Option {
+	return func(o *options) {
+		o.healthCallback = fn
+	}
+}
+
+// WithLogHandler sets the slog handler which receives the logs of the client and of the engine process,
+// instead of the default logger of the logger package. Database queries are logged at debug level.
+func WithLogHandler(h slog.Handler) Option {
+	return func(o *options) {
+		o.logHandler = h
+	}
+}
+
+// WithLogLevel sets the minimum level of engine logs, which also applies to a handler set via WithLogHandler.
+// Defaults to error, or debug if the logger of the client has debug logs enabled, e.g. via PRISMA_CLIENT_GO_LOG.
+func WithLogLevel(level slog.Level) Option {
+	return func(o *options) {
+		o.logLevel = &level
+	}
+}
+
+// DefaultRequestTimeout is the request timeout of engines created without WithRequestTimeout.
+// Zero means requests are only limited by their context.
+var DefaultRequestTimeout = time.Minute
+
+func newOptions(opts []Option) options {
+	o := options{
+		requestTimeout: DefaultRequestTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.logHandler != nil {
+		o.logs = slog.New(o.logHandler)
+	}
+	return o
+}
+
+// engineLogLevel returns the minimum level of engine logs
+func (o options) engineLogLevel() slog.Level {
+	if o.logLevel != nil {
+		return *o.logLevel
+	}
+	if o.log().Enabled(context.Background(), slog.LevelDebug) {
+		return slog.LevelDebug
+	}
+	return slog.LevelError
+}
+
+// log returns the logger of the client
+func (o options) log() *slog.Logger {
+	if o.logs != nil {
+		return o.logs
+	}
+	return logger.Default()
+}
+
+// engineLog returns the logger for engine logs, which drops logs below the engine log level
+func (o options) engineLog() *slog.Logger {
+	return slog.New(&levelHandler{
+		Handler: o.log().Handler(),
+		level:   o.engineLogLevel(),
+	})
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type countingTransport struct {
+	count int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestQueryEngine_options(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sleep") != "" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T)
+	}{{
+		name: "request timeout",
+		run: func(t *testing.T) {
+			e := NewQueryEngine("", false, WithRequestTimeout(50*time.Millisecond))
+			e.url = server.URL
+
+			_, err := e.Request(context.Background(), "POST", "/?sleep=1", map[string]interface{}{})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected deadline exceeded, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "request timeout of 50ms exceeded") {
+				t.Fatalf("expected request timeout error, got %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err = e.Request(ctx, "POST", "/?sleep=1", map[string]interface{}{})
+			if !errors.Is(err, context.DeadlineExceeded) || strings.Contains(err.Error(), "request timeout") {
+				t.Fatalf("expected deadline of the context to be exceeded, got %v", err)
+			}
+
+			if _, err := e.Request(context.Background(), "POST", "/", map[string]interface{}{}); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		},
+	}, {
+		name: "http client",
+		run: func(t *testing.T) {
+			transport := &countingTransport{}
+			e := NewQueryEngine("", false, WithHTTPClient(&http.Client{Transport: transport}))
+			e.url = server.URL
+
+			if _, err := e.Request(context.Background(), "POST", "/", map[string]interface{}{}); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if transport.count != 1 {
+				t.Fatalf("expected custom http client to be used once, got %d", transport.count)
+			}
+		},
+	}, {
+		name: "http client timeout",
+		run: func(t *testing.T) {
+			e := NewQueryEngine("", false, WithHTTPClient(&http.Client{Timeout: 50 * time.Millisecond}))
+			e.url = server.URL
+
+			_, err := e.Request(context.Background(), "POST", "/?sleep=1", map[string]interface{}{})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected deadline exceeded, got %v", err)
+			}
+		},
+	}, {
+		name: "default request timeout",
+		run: func(t *testing.T) {
+			if e := NewQueryEngine("", false); e.opts.requestTimeout != time.Minute {
+				t.Fatalf("expected a request timeout of one minute by default, got %s", e.opts.requestTimeout)
+			}
+
+			defer func(d time.Duration) { DefaultRequestTimeout = d }(DefaultRequestTimeout)
+			DefaultRequestTimeout = 50 * time.Millisecond
+
+			if e := NewQueryEngine("", false); e.opts.requestTimeout != DefaultRequestTimeout {
+				t.Fatalf("expected default request timeout, got %s", e.opts.requestTimeout)
+			}
+			if e := NewQueryEngine("", false, WithRequestTimeout(0)); e.opts.requestTimeout != 0 {
+				t.Fatalf("expected request timeout to be disabled, got %s", e.opts.requestTimeout)
+			}
+		},
+	}, {
+		name: "log handler",
+		run: func(t *testing.T) {
+			var buf bytes.Buffer
+			e := NewQueryEngine("", false, WithLogHandler(slog.NewJSONHandler(&buf, nil)))
+			e.disconnected = true
+
+			if _, err := e.Request(context.Background(), "POST", "/", map[string]interface{}{}); err == nil {
+				t.Fatalf("expected error after disconnect")
+			}
+			if !strings.Contains(buf.String(), `"level":"WARN"`) || !strings.Contains(buf.String(), "after Disconnect() was called") {
+				t.Fatalf("expected warning to be logged via handler, got %q", buf.String())
+			}
+			if Logger(e).Handler() != e.opts.logHandler {
+				t.Fatalf("expected Logger to use the handler of the engine")
+			}
+			if Logger(e) != Logger(e) {
+				t.Fatalf("expected the logger to be built once")
+			}
+		},
+	}, {
+		name: "log level with log handler",
+		run: func(t *testing.T) {
+			var buf bytes.Buffer
+			h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+			e := NewQueryEngine("", false, WithLogHandler(h), WithLogLevel(slog.LevelWarn))
+
+			log := e.opts.engineLog()
+			log.Info("dropped")
+			log.Warn("kept")
+
+			if strings.Contains(buf.String(), "dropped") || !strings.Contains(buf.String(), "kept") {
+				t.Fatalf("expected engine logs below the log level to be dropped, got %q", buf.String())
+			}
+		},
+	}, {
+		name: "binary path not found",
+		run: func(t *testing.T) {
+			e := NewQueryEngine("", false, WithBinaryPath("/does/not/exist/query-engine"))
+
+			_, err := e.ensure(context.Background())
+			if err == nil || !strings.Contains(err.Error(), "/does/not/exist/query-engine") {
+				t.Fatalf("expected binary path error, got %v", err)
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, tt.run)
+	}
+}
@@ -2,7 +2,10 @@ package engine
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
+
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
 )
 
 // GQLResponse is the default GraphQL response
@@ -31,6 +34,27 @@ type GQLRequest struct {
 type GQLBatchRequest struct {
 	Batch       []GQLRequest `json:"batch"`
 	Transaction bool         `json:"transaction"`
+	// IsolationLevel (optional) sets the isolation level of the transaction
+	IsolationLevel string `json:"-"`
+}
+
+type batchTransaction struct {
+	IsolationLevel string `json:"isolationLevel"`
+}
+
+// MarshalJSON sends the transaction as an object when an isolation level is set
+func (r GQLBatchRequest) MarshalJSON() ([]byte, error) {
+	var transaction interface{} = r.Transaction
+	if r.Transaction && r.IsolationLevel != "" {
+		transaction = batchTransaction{IsolationLevel: r.IsolationLevel}
+	}
+	return json.Marshal(struct {
+		Batch       []GQLRequest `json:"batch"`
+		Transaction interface{}  `json:"transaction"`
+	}{
+		Batch:       r.Batch,
+		Transaction: transaction,
+	})
 }
 
 // GQLError is a GraphQL Error
@@ -38,8 +62,36 @@ type GQLError struct {
 	Message    string                 `json:"error"` // note: the query-engine uses 'error' instead of 'message'
 	Path       []string               `json:"path"`
 	Extensions map[string]interface{} `json:"query"`
+	// UserFacingError (optional) contains the structured error including the Prisma error code
+	UserFacingError *UserFacingError `json:"user_facing_error"`
+}
+
+// UserFacingError is the structured part of a query engine error
+type UserFacingError struct {
+	IsPanic   bool                   `json:"is_panic"`
+	Message   string                 `json:"message"`
+	Meta      map[string]interface{} `json:"meta"`
+	ErrorCode string                 `json:"error_code"`
 }
 
 func (e *GQLError) RawMessage() string {
 	return strings.ReplaceAll(e.Message, "\n", " ")
 }
+
+// Err converts the GraphQL error into a Go error. Errors with a Prisma error code are returned
+// as types.Error or one of its more specific variants, so they can be inspected with errors.As.
+// P2025 is returned as types.ErrNotFound itself, so comparing the error to it keeps working.
+func (e *GQLError) Err() error {
+	if f := e.UserFacingError; f != nil && f.ErrorCode != "" {
+		if types.ErrorCode(f.ErrorCode) == types.ErrCodeRecordNotFound {
+			return types.ErrNotFound
+		}
+		return fmt.Errorf("pql error: %w", types.NewError(types.ErrorCode(f.ErrorCode), f.Message, f.Meta))
+	}
+
+	if e.RawMessage() == internalUpdateNotFoundMessage || e.RawMessage() == internalDeleteNotFoundMessage {
+		return types.ErrNotFound
+	}
+
+	return fmt.Errorf("pql error: %s", e.RawMessage())
+}
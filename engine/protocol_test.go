@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
+)
+
+func TestGQLBatchRequest_MarshalJSON(t *testing.T) {
+	batch := []GQLRequest{{Query: "query {}", Variables: map[string]interface{}{}}}
+
+	tests := []struct {
+		name    string
+		request GQLBatchRequest
+		want    string
+	}{{
+		name: "batch",
+		request: GQLBatchRequest{
+			Batch: batch,
+		},
+		want: `{"batch":[{"query":"query {}","variables":{}}],"transaction":false}`,
+	}, {
+		name: "transaction",
+		request: GQLBatchRequest{
+			Batch:       batch,
+			Transaction: true,
+		},
+		want: `{"batch":[{"query":"query {}","variables":{}}],"transaction":true}`,
+	}, {
+		name: "transaction with isolation level",
+		request: GQLBatchRequest{
+			Batch:          batch,
+			Transaction:    true,
+			IsolationLevel: "Serializable",
+		},
+		want: `{"batch":[{"query":"query {}","variables":{}}],"transaction":{"isolationLevel":"Serializable"}}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGQLError_Err(t *testing.T) {
+	tests := []struct {
+		name  string
+		error GQLError
+		check func(t *testing.T, err error)
+	}{{
+		name: "unique constraint",
+		error: GQLError{
+			Message: "Unique constraint failed on the fields: (`email`)",
+			UserFacingError: &UserFacingError{
+				Message:   "Unique constraint failed on the fields: (`email`)",
+				ErrorCode: "P2002",
+				Meta:      map[string]interface{}{"target": []interface{}{"email"}},
+			},
+		},
+		check: func(t *testing.T, err error) {
+			info, ok := types.CheckUniqueConstraint(err)
+			if !ok {
+				t.Fatalf("expected unique constraint error, got %v", err)
+			}
+			if len(info.Fields) != 1 || info.Fields[0] != "email" {
+				t.Errorf("Fields = %v, want [email]", info.Fields)
+			}
+			var e *types.Error
+			if !errors.As(err, &e) || e.Code != types.ErrCodeUniqueConstraint {
+				t.Errorf("expected types.Error with code P2002, got %v", err)
+			}
+		},
+	}, {
+		name: "foreign key constraint",
+		error: GQLError{
+			UserFacingError: &UserFacingError{
+				Message:   "Foreign key constraint failed on the field: `Post_authorId_fkey (index)`",
+				ErrorCode: "P2003",
+				Meta:      map[string]interface{}{"field_name": "Post_authorId_fkey (index)"},
+			},
+		},
+		check: func(t *testing.T, err error) {
+			info, ok := types.CheckForeignKeyConstraint(err)
+			if !ok {
+				t.Fatalf("expected foreign key constraint error, got %v", err)
+			}
+			if info.Field != "Post_authorId_fkey (index)" {
+				t.Errorf("Field = %s, want Post_authorId_fkey (index)", info.Field)
+			}
+		},
+	}, {
+		name: "not found",
+		error: GQLError{
+			UserFacingError: &UserFacingError{
+				Message:   "An operation failed because it depends on one or more records that were required but not found.",
+				ErrorCode: "P2025",
+			},
+		},
+		check: func(t *testing.T, err error) {
+			if err != types.ErrNotFound {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+			if _, ok := types.CheckErrorCode(err, types.ErrCodeRecordNotFound); !ok {
+				t.Errorf("expected error code P2025, got %v", err)
+			}
+		},
+	}, {
+		name: "legacy not found",
+		error: GQLError{
+			Message: internalUpdateNotFoundMessage,
+		},
+		check: func(t *testing.T, err error) {
+			if err != types.ErrNotFound {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		},
+	}, {
+		name: "other code",
+		error: GQLError{
+			UserFacingError: &UserFacingError{
+				Message:   "Transaction failed due to a write conflict or a deadlock.",
+				ErrorCode: "P2034",
+			},
+		},
+		check: func(t *testing.T, err error) {
+			if _, ok := types.CheckErrorCode(err, types.ErrCodeTransactionConflict); !ok {
+				t.Errorf("expected error with code P2034, got %v", err)
+			}
+		},
+	}, {
+		name: "unknown",
+		error: GQLError{
+			Message: "something\nwent wrong",
+		},
+		check: func(t *testing.T, err error) {
+			if err.Error() != "pql error: something went wrong" {
+				t.Errorf("unexpected error %v", err)
+			}
+			var e *types.Error
+			if errors.As(err, &e) {
+				t.Errorf("expected untyped error, got %v", e)
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, tt.error.Err())
+		})
+	}
+}
@@ -13,16 +13,22 @@ import (
 	"time"
 
 	"github.com/vnsoft2014/prisma-client-go/binaries"
-	"github.com/vnsoft2014/prisma-client-go/logger"
-	"github.com/vnsoft2014/prisma-client-go/runtime/types"
 )
 
-func NewDataProxyEngine(schema, connectionURL string) *DataProxyEngine {
-	return &DataProxyEngine{
+func NewDataProxyEngine(schema, connectionURL string, opts ...Option) *DataProxyEngine {
+	o := newOptions(opts)
+	e := &DataProxyEngine{
 		Schema:        schema,
 		connectionURL: connectionURL,
-		http:          &http.Client{},
+		http:          o.httpClient,
+		opts:          o,
 	}
+	if e.http == nil {
+		e.http = &http.Client{
+			Transport: newTransport(o.transport, ""),
+		}
+	}
+	return e
 }
 
 type DataProxyEngine struct {
@@ -41,14 +47,21 @@ type DataProxyEngine struct {
 
 	// apiKey contains the parsed prisma data proxy api key from the connection string
 	apiKey string
+
+	// opts contains the options the engine was created with
+	opts options
 }
 
 func (e *DataProxyEngine) Connect() error {
+	return e.ConnectContext(context.Background())
+}
+
+// ConnectContext uploads the schema to the data proxy, or stops when ctx is done
+func (e *DataProxyEngine) ConnectContext(ctx context.Context) error {
 	// Example uri: https://aws-eu-west-1.prisma-data.com/2.26.0/412bf0a1742a576d699fbd5102a4f725557eff3992995f2e18febce128794961/
+	log := e.opts.log()
 	hash := hashSchema(e.Schema)
-	logger.Debug.Printf("local schema hash %s", hash)
-
-	logger.Debug.Printf("parsing connection string from database url %s", e.connectionURL)
+	log.Debug("parsing connection string from database url", "hash", hash)
 
 	u, err := url.Parse(e.connectionURL)
 	if err != nil {
@@ -60,8 +73,13 @@ func (e *DataProxyEngine) Connect() error {
 	}
 
 	e.url = getCloudURI(u.Host, hash)
-	logger.Debug.Printf("using %s as remote URI", e.url)
-	if err := e.uploadSchema(context.Background()); err != nil {
+	log.Debug("using remote URI", "url", e.url)
+	if e.opts.connectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.opts.connectTimeout)
+		defer cancel()
+	}
+	if err := e.uploadSchema(ctx); err != nil {
 		return fmt.Errorf("upload schema: %w", err)
 	}
 
@@ -69,13 +87,13 @@ func (e *DataProxyEngine) Connect() error {
 }
 
 func (e *DataProxyEngine) uploadSchema(ctx context.Context) error {
-	logger.Debug.Printf("uploading schema...")
+	log := e.opts.log()
+	log.Debug("uploading schema...")
 	b64Schema := encodeSchema(e.Schema)
 	res, err := e.request(ctx, "PUT", "/schema", []byte(b64Schema))
 	if err != nil {
 		return fmt.Errorf("put schema: %w", err)
 	}
-	logger.Debug.Printf("schema upload response: %s", res)
 	type SchemaResponse struct {
 		SchemaHash string `json:"schemaHash"`
 	}
@@ -83,8 +101,7 @@ func (e *DataProxyEngine) uploadSchema(ctx context.Context) error {
 	if err := json.Unmarshal(res, &response); err != nil {
 		return fmt.Errorf("schema response err: %w", err)
 	}
-	logger.Debug.Printf("remote schema hash %s", response.SchemaHash)
-	logger.Debug.Printf("schema upload done.")
+	log.Debug("schema upload done", "hash", response.SchemaHash)
 	return nil
 }
 
@@ -104,7 +121,8 @@ func (e *DataProxyEngine) Do(ctx context.Context, payload interface{}, into inte
 		return fmt.Errorf("request failed: %w", err)
 	}
 
-	logger.Debug.Printf("[timing] query engine request took %s", time.Since(startReq))
+	log := e.opts.log()
+	log.DebugContext(ctx, "query engine request done", "duration", time.Since(startReq))
 
 	startParse := time.Now()
 
@@ -115,18 +133,14 @@ func (e *DataProxyEngine) Do(ctx context.Context, payload interface{}, into inte
 
 	if len(response.Errors) > 0 {
 		first := response.Errors[0]
-		if first.RawMessage() == internalUpdateNotFoundMessage ||
-			first.RawMessage() == internalDeleteNotFoundMessage {
-			return types.ErrNotFound
-		}
-		return fmt.Errorf("pql error: %s", first.RawMessage())
+		return first.Err()
 	}
 
 	if err := json.Unmarshal(response.Data.Result, into); err != nil {
 		return fmt.Errorf("json data result unmarshal: %w", err)
 	}
 
-	logger.Debug.Printf("[timing] request unmarshal took %s", time.Since(startParse))
+	log.DebugContext(ctx, "request unmarshal done", "duration", time.Since(startParse))
 
 	return nil
 }
@@ -154,11 +168,13 @@ func (e *DataProxyEngine) Name() string {
 }
 
 func (e *DataProxyEngine) request(ctx context.Context, method string, path string, payload []byte) ([]byte, error) {
-	logger.Debug.Printf("requesting %s", e.url+path)
 	auth := func(req *http.Request) {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", e.apiKey))
 	}
-	return request(ctx, e.http, method, e.url+path, payload, auth)
+	ctx, cancel, wrap := withRequestTimeout(ctx, e.opts.requestTimeout)
+	defer cancel()
+	body, err := request(ctx, e.opts.log(), e.http, method, e.url+path, payload, auth)
+	return body, wrap(err)
 }
 
 func (e *DataProxyEngine) retryableRequest(ctx context.Context, method string, path string, payload []byte) ([]byte, error) {
@@ -167,11 +183,11 @@ func (e *DataProxyEngine) retryableRequest(ctx context.Context, method string, p
 		if !errors.Is(err, errNotFound) {
 			return nil, err
 		}
-		logger.Debug.Printf("got status not found in data proxy request; re-uploading schema")
+		e.opts.log().Debug("got status not found in data proxy request; re-uploading schema")
 		if err := e.uploadSchema(ctx); err != nil {
 			return nil, fmt.Errorf("upload schema after 400 request: %w", err)
 		}
-		logger.Debug.Printf("schema re-upload succeeded")
+		e.opts.log().Debug("schema re-upload succeeded")
 		return e.request(ctx, method, path, payload)
 	}
 	return res, nil
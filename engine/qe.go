@@ -3,14 +3,27 @@ package engine
 import (
 	"net/http"
 	"os/exec"
+	"sync"
+	"time"
 )
 
-func NewQueryEngine(schema string, hasBinaryTargets bool) *QueryEngine {
-	return &QueryEngine{
+func NewQueryEngine(schema string, hasBinaryTargets bool, opts ...Option) *QueryEngine {
+	o := newOptions(opts)
+	e := &QueryEngine{
 		Schema:           schema,
 		hasBinaryTargets: hasBinaryTargets,
-		http:             &http.Client{},
+		http:             o.httpClient,
+		opts:             o,
 	}
+	if o.unixSocket {
+		e.socket = socketPath(o.socketDir)
+	}
+	if e.http == nil {
+		e.http = &http.Client{
+			Transport: newTransport(o.transport, e.socket),
+		}
+	}
+	return e
 }
 
 type QueryEngine struct {
@@ -23,6 +36,9 @@ type QueryEngine struct {
 	// url holds the query-engine url
 	url string
 
+	// socket is the path of the unix domain socket the engine listens on; empty if it listens on a tcp port
+	socket string
+
 	// Schema contains the prisma Schema
 	Schema string
 
@@ -31,6 +47,49 @@ type QueryEngine struct {
 	hasBinaryTargets bool
 
 	disconnected bool
+
+	// mu protects the fields below as well as cmd, url and disconnected, which change when the engine
+	// is restarted or disconnected
+	mu sync.Mutex
+
+	// exited is closed by the supervisor when the current engine process exits
+	exited chan struct{}
+
+	// exitErr contains the result of waiting for the current engine process after it exited
+	exitErr error
+
+	// crashErr is set when the engine process exited unexpectedly and cleared when it was restarted
+	crashErr error
+
+	// restarts counts how often the engine was restarted after crashing
+	restarts int
+
+	// version is the version reported by the engine binary
+	version string
+
+	// startedAt is the time the current engine process was started
+	startedAt time.Time
+
+	// lastErr is the last crash or failed health check of the engine
+	lastErr error
+
+	// ready is closed when the engine is connected for the first time
+	ready chan struct{}
+
+	// stop is closed on Disconnect to stop restarting the engine
+	stop chan struct{}
+
+	// active counts the requests which are currently in flight
+	active int
+
+	// drained is closed when the last in-flight request finished while disconnecting
+	drained chan struct{}
+
+	// queryHooks are called for every database query logged by the engine
+	queryHooks []func(QueryEvent)
+
+	// opts contains the options the engine was created with
+	opts options
 }
 
 func (e *QueryEngine) Name() string {
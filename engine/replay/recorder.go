@@ -0,0 +1,154 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+// Recorder sends requests to an engine and records them and their responses.
+// The golden file is written when disconnecting.
+type Recorder struct {
+	// Engine is the engine requests are sent to
+	Engine engine.Engine
+
+	path         string
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder returns a Recorder which sends requests to e and records them in the golden file at path
+func NewRecorder(e engine.Engine, path string) *Recorder {
+	return &Recorder{
+		Engine: e,
+		path:   path,
+	}
+}
+
+func (r *Recorder) Name() string {
+	return r.Engine.Name()
+}
+
+func (r *Recorder) Connect() error {
+	return r.Engine.Connect()
+}
+
+func (r *Recorder) ConnectContext(ctx context.Context) error {
+	return engine.Connect(ctx, r.Engine)
+}
+
+// Disconnect disconnects the engine and writes the golden file
+func (r *Recorder) Disconnect() error {
+	if err := r.Engine.Disconnect(); err != nil {
+		return err
+	}
+	return r.Save()
+}
+
+// DisconnectContext gracefully disconnects the engine and writes the golden file
+func (r *Recorder) DisconnectContext(ctx context.Context) error {
+	if g, ok := r.Engine.(engine.GracefulDisconnecter); ok {
+		if err := g.DisconnectContext(ctx); err != nil {
+			return err
+		}
+		return r.Save()
+	}
+	return r.Disconnect()
+}
+
+// Save writes all interactions recorded so far to the golden file
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return save(r.path, r.interactions)
+}
+
+// Interactions returns the interactions recorded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+func (r *Recorder) Logger() *slog.Logger {
+	return engine.Logger(r.Engine)
+}
+
+func (r *Recorder) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	err := r.Engine.Do(ctx, payload, v)
+	return r.record(ctx, KindDo, payload, v, err)
+}
+
+func (r *Recorder) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	err := r.Engine.Batch(ctx, payload, v)
+	return r.record(ctx, KindBatch, payload, v, err)
+}
+
+func (r *Recorder) StartTx(ctx context.Context, opts engine.TxOptions) (string, error) {
+	t, err := r.transactional()
+	if err != nil {
+		return "", err
+	}
+	id, err := t.StartTx(ctx, opts)
+	return id, r.record(ctx, KindStartTx, opts, id, err)
+}
+
+func (r *Recorder) CommitTx(ctx context.Context, id string) error {
+	t, err := r.transactional()
+	if err != nil {
+		return err
+	}
+	err = t.CommitTx(ctx, id)
+	return r.record(engine.WithTxID(ctx, id), KindCommitTx, nil, nil, err)
+}
+
+func (r *Recorder) RollbackTx(ctx context.Context, id string) error {
+	t, err := r.transactional()
+	if err != nil {
+		return err
+	}
+	err = t.RollbackTx(ctx, id)
+	return r.record(engine.WithTxID(ctx, id), KindRollbackTx, nil, nil, err)
+}
+
+func (r *Recorder) transactional() (engine.Transactional, error) {
+	t, ok := r.Engine.(engine.Transactional)
+	if !ok {
+		return nil, fmt.Errorf("engine %s does not support interactive transactions", r.Engine.Name())
+	}
+	return t, nil
+}
+
+// record records a request and returns the error of the request, or an error if it could not be recorded
+func (r *Recorder) record(ctx context.Context, kind Kind, payload interface{}, v interface{}, err error) error {
+	interaction := Interaction{
+		Kind: kind,
+		TxID: engine.TxID(ctx),
+	}
+	if payload != nil {
+		request, encErr := json.Marshal(payload)
+		if encErr != nil {
+			return fmt.Errorf("encode request for recording: %w", encErr)
+		}
+		interaction.Request = request
+	}
+	if err != nil {
+		interaction.Error = newError(err)
+	} else if v != nil {
+		response, encErr := json.Marshal(v)
+		if encErr != nil {
+			return fmt.Errorf("encode response for recording: %w", encErr)
+		}
+		interaction.Response = response
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+
+	return err
+}
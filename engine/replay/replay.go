@@ -0,0 +1,227 @@
+// Package replay records the requests a client sends to the query engine and their responses in golden files,
+// and replays them instead of sending the requests to the query engine, so tests get deterministic responses.
+// Golden files have to be recorded against a real database first, and recorded again whenever the queries of a
+// test change.
+//
+// Whether requests are recorded or replayed is selected via the PRISMA_CLIENT_GO_REPLAY env var:
+//
+//	PRISMA_CLIENT_GO_REPLAY=record go test ./...  # run against a real database and write the golden files
+//	PRISMA_CLIENT_GO_REPLAY=replay go test ./...  # serve the recorded responses instead of the query engine
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
+)
+
+// Env is the name of the env var which selects the Mode
+const Env = "PRISMA_CLIENT_GO_REPLAY"
+
+// Mode describes whether requests are recorded, replayed or sent to the query engine as usual
+type Mode string
+
+const (
+	// ModeOff sends requests to the query engine without recording them
+	ModeOff Mode = ""
+	// ModeRecord sends requests to the query engine and writes them and their responses to golden files
+	ModeRecord Mode = "record"
+	// ModeReplay serves responses from golden files without a query engine
+	ModeReplay Mode = "replay"
+)
+
+// ModeFromEnv returns the mode set via the Env env var. Unknown values return an error, so typos don't silently
+// run tests against a real database.
+func ModeFromEnv() (Mode, error) {
+	switch m := Mode(os.Getenv(Env)); m {
+	case ModeOff, ModeRecord, ModeReplay:
+		return m, nil
+	default:
+		return ModeOff, fmt.Errorf("%s must be either %q or %q, but is %q", Env, ModeRecord, ModeReplay, m)
+	}
+}
+
+// New wraps e in a Recorder or returns a Replayer, depending on the mode set via the Env env var.
+// Without the env var, e is returned as is. If the env var is invalid, the returned engine fails to connect.
+// Engines which route reads to read replicas can't be recorded or replayed, as reads would bypass the recording,
+// so the returned engine fails to connect in that case as well.
+func New(e engine.Engine, path string) engine.Engine {
+	mode, err := ModeFromEnv()
+	if err != nil {
+		return &invalid{err: err}
+	}
+	if _, ok := e.(engine.ReadRouter); ok && mode != ModeOff {
+		return &invalid{err: fmt.Errorf("requests to read replicas can't be recorded or replayed; remove the read replicas when %s is set", Env)}
+	}
+	switch mode {
+	case ModeRecord:
+		return NewRecorder(e, path)
+	case ModeReplay:
+		return NewReplayer(path)
+	default:
+		return e
+	}
+}
+
+// Wrap returns an engine.Wrapper which passes the engine of a client to New, e.g.
+//
+//	client := db.NewClient(db.WithEngine(replay.Wrap(replay.GoldenPath(t))))
+func Wrap(path string) engine.Wrapper {
+	return func(_ string, e engine.Engine) engine.Engine {
+		return New(e, path)
+	}
+}
+
+// invalid is returned by New if the mode is invalid, and returns the error for all requests
+type invalid struct {
+	err error
+}
+
+func (e *invalid) Name() string {
+	return "replay"
+}
+
+func (e *invalid) Connect() error {
+	return e.err
+}
+
+func (e *invalid) Disconnect() error {
+	return nil
+}
+
+func (e *invalid) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	return e.err
+}
+
+func (e *invalid) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	return e.err
+}
+
+// GoldenPath returns the path of the golden file of a test, testdata/replay/<test name>.json
+func GoldenPath(t testing.TB) string {
+	return filepath.Join("testdata", "replay", t.Name()+".json")
+}
+
+// Kind describes the type of request of an Interaction
+type Kind string
+
+const (
+	KindDo         Kind = "do"
+	KindBatch      Kind = "batch"
+	KindStartTx    Kind = "startTx"
+	KindCommitTx   Kind = "commitTx"
+	KindRollbackTx Kind = "rollbackTx"
+)
+
+// Interaction is a request to the query engine and its response, as stored in golden files
+type Interaction struct {
+	Kind Kind `json:"kind"`
+
+	// TxID is the id of the interactive transaction the request was sent in
+	TxID string `json:"txId,omitempty"`
+
+	// Request is the payload of the request
+	Request json.RawMessage `json:"request,omitempty"`
+
+	// Response is the value the response was decoded into; empty if the request failed
+	Response json.RawMessage `json:"response,omitempty"`
+
+	// Error is the error the request failed with
+	Error *Error `json:"error,omitempty"`
+}
+
+// Error is an error returned by the query engine, as stored in golden files
+type Error struct {
+	Message string `json:"message"`
+
+	// Code is the Prisma error code, if any; errors with a code are replayed as the same types.Error
+	Code types.ErrorCode `json:"code,omitempty"`
+
+	Meta map[string]interface{} `json:"meta,omitempty"`
+
+	// NotFound is true for ErrNotFound without an error code, e.g. if FindUnique found no record
+	NotFound bool `json:"notFound,omitempty"`
+}
+
+// newError converts an error returned by the engine so it can be stored in a golden file
+func newError(err error) *Error {
+	var e *types.Error
+	if errors.As(err, &e) {
+		return &Error{Message: e.Message, Code: e.Code, Meta: e.Meta}
+	}
+	if errors.Is(err, types.ErrNotFound) {
+		return &Error{Message: err.Error(), NotFound: true}
+	}
+	return &Error{Message: err.Error()}
+}
+
+// Err returns the error as it was returned by the engine
+func (e *Error) Err() error {
+	if e.NotFound {
+		return types.ErrNotFound
+	}
+	if e.Code == "" {
+		return errors.New(e.Message)
+	}
+	gql := engine.GQLError{
+		UserFacingError: &engine.UserFacingError{
+			ErrorCode: string(e.Code),
+			Message:   e.Message,
+			Meta:      e.Meta,
+		},
+	}
+	return gql.Err()
+}
+
+// load reads the interactions of a golden file
+func load(path string) ([]Interaction, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no recording found at %s; record it by running the test with %s=%s: %w", path, Env, ModeRecord, err)
+		}
+		return nil, fmt.Errorf("read recording: %w", err)
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(b, &interactions); err != nil {
+		return nil, fmt.Errorf("decode recording %s: %w", path, err)
+	}
+	// requests are compared byte by byte, but golden files are indented
+	for i, interaction := range interactions {
+		if interaction.Request == nil {
+			continue
+		}
+		var b bytes.Buffer
+		if err := json.Compact(&b, interaction.Request); err != nil {
+			return nil, fmt.Errorf("decode recording %s: %w", path, err)
+		}
+		interactions[i].Request = b.Bytes()
+	}
+	return interactions, nil
+}
+
+// save writes the interactions to a golden file, creating its directory if needed
+func save(path string, interactions []Interaction) error {
+	if interactions == nil {
+		interactions = []Interaction{}
+	}
+	b, err := json.MarshalIndent(interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode recording: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create recording directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("write recording: %w", err)
+	}
+	return nil
+}
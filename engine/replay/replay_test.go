@@ -0,0 +1,260 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
+)
+
+// fakeEngine answers queries with a user with the query as id, and fails queries containing "missing" or "duplicate"
+type fakeEngine struct {
+	connected bool
+}
+
+func (e *fakeEngine) Name() string { return "fake" }
+
+func (e *fakeEngine) Connect() error {
+	e.connected = true
+	return nil
+}
+
+func (e *fakeEngine) Disconnect() error {
+	e.connected = false
+	return nil
+}
+
+func (e *fakeEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	query := payload.(engine.GQLRequest).Query
+	if strings.Contains(query, "missing") {
+		return types.ErrNotFound
+	}
+	if strings.Contains(query, "duplicate") {
+		return (&engine.GQLError{UserFacingError: &engine.UserFacingError{
+			ErrorCode: string(types.ErrCodeUniqueConstraint),
+			Message:   "Unique constraint failed on the fields: (`email`)",
+			Meta:      map[string]interface{}{"target": []interface{}{"email"}},
+		}}).Err()
+	}
+	b, err := json.Marshal(user{ID: query})
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (e *fakeEngine) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	var response engine.GQLBatchResponse
+	for _, q := range payload.(engine.GQLBatchRequest).Batch {
+		var result engine.GQLResponse
+		b, err := json.Marshal(user{ID: q.Query})
+		if err != nil {
+			return err
+		}
+		result.Data.Result = b
+		response.Result = append(response.Result, result)
+	}
+	b, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (e *fakeEngine) StartTx(ctx context.Context, opts engine.TxOptions) (string, error) {
+	return "tx-1", nil
+}
+
+func (e *fakeEngine) CommitTx(ctx context.Context, id string) error {
+	return nil
+}
+
+func (e *fakeEngine) RollbackTx(ctx context.Context, id string) error {
+	return nil
+}
+
+type user struct {
+	ID string `json:"id"`
+}
+
+func query(q string) engine.GQLRequest {
+	return engine.GQLRequest{Query: q, Variables: map[string]interface{}{}}
+}
+
+// run sends the same requests to an engine and returns all results and errors
+func run(t *testing.T, e engine.Engine) []interface{} {
+	t.Helper()
+	ctx := context.Background()
+	var results []interface{}
+
+	for _, q := range []string{"a", "b", "a", "missing", "duplicate"} {
+		var u user
+		err := e.Do(ctx, query(q), &u)
+		results = append(results, u, err)
+	}
+
+	var batch engine.GQLBatchResponse
+	err := e.Batch(ctx, engine.GQLBatchRequest{
+		Batch:       []engine.GQLRequest{query("x"), query("y")},
+		Transaction: true,
+	}, &batch)
+	results = append(results, batch, err)
+
+	tx := e.(engine.Transactional)
+	id, err := tx.StartTx(ctx, engine.TxOptions{})
+	results = append(results, id, err)
+	var u user
+	err = e.Do(engine.WithTxID(ctx, id), query("in tx"), &u)
+	results = append(results, u, err)
+	results = append(results, tx.CommitTx(ctx, id))
+
+	return results
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "golden.json")
+
+	recorder := NewRecorder(&fakeEngine{}, path)
+	if err := recorder.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	recorded := run(t, recorder)
+	if err := recorder.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer := NewReplayer(path)
+	if err := replayer.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	replayed := run(t, replayer)
+
+	if len(recorded) != len(replayed) {
+		t.Fatalf("expected %d results, got %d", len(recorded), len(replayed))
+	}
+	for i := range recorded {
+		r, p := recorded[i], replayed[i]
+		if err, ok := r.(error); ok {
+			if p == nil || err.Error() != p.(error).Error() {
+				t.Errorf("result %d: expected error %v, got %v", i, err, p)
+			}
+			continue
+		}
+		rb, _ := json.Marshal(r)
+		pb, _ := json.Marshal(p)
+		if string(rb) != string(pb) {
+			t.Errorf("result %d: expected %s, got %s", i, rb, pb)
+		}
+	}
+
+	// errors keep their type
+	if !errors.Is(replayed[7].(error), types.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", replayed[7])
+	}
+	if _, ok := types.CheckUniqueConstraint(replayed[9].(error)); !ok {
+		t.Errorf("expected a unique constraint error, got %v", replayed[9])
+	}
+
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("expected all interactions to be used, got %+v", unused)
+	}
+}
+
+func TestReplayer_unknown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.json")
+
+	recorder := NewRecorder(&fakeEngine{}, path)
+	var u user
+	if err := recorder.Do(context.Background(), query(`query {result: findUniqueUser(where:{id:"123",},) {id }}`), &u); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer := NewReplayer(path)
+	if err := replayer.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := replayer.Do(context.Background(), query(`query {result: findUniqueUser(where:{id:"456",},) {id }}`), &u)
+	if !errors.Is(err, ErrUnknownRequest) {
+		t.Fatalf("expected ErrUnknownRequest, got %v", err)
+	}
+	expected := `- recorded: {"query":"query {result: findUniqueUser(where:{id:\"123\",},) {id }}","variables":{}}
++ actual:   {"query":"query {result: findUniqueUser(where:{id:\"456\",},) {id }}","variables":{}}
+                                                                ^`
+	if !strings.HasSuffix(err.Error(), expected) {
+		t.Errorf("expected diff\n%s\ngot\n%s", expected, err)
+	}
+
+	if err := replayer.Do(context.Background(), query(`query {result: findUniqueUser(where:{id:"123",},) {id }}`), &u); err != nil {
+		t.Fatal(err)
+	}
+	err = replayer.Do(context.Background(), query(`query {result: findUniqueUser(where:{id:"123",},) {id }}`), &u)
+	if !errors.Is(err, ErrUnknownRequest) || !strings.Contains(err.Error(), "more often than recorded") {
+		t.Errorf("expected an error for a request sent more often than recorded, got %v", err)
+	}
+}
+
+func TestReplayer_missing(t *testing.T) {
+	err := NewReplayer(filepath.Join(t.TempDir(), "missing.json")).Connect()
+	if err == nil || !strings.Contains(err.Error(), Env+"=record") {
+		t.Errorf("expected an error explaining how to record, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	e := &fakeEngine{}
+
+	t.Setenv(Env, "")
+	if New(e, "golden.json") != engine.Engine(e) {
+		t.Errorf("expected the engine to be used as is")
+	}
+
+	t.Setenv(Env, "record")
+	if _, ok := New(e, "golden.json").(*Recorder); !ok {
+		t.Errorf("expected a recorder")
+	}
+
+	t.Setenv(Env, "replay")
+	if _, ok := New(e, "golden.json").(*Replayer); !ok {
+		t.Errorf("expected a replayer")
+	}
+
+	replicas := engine.NewReplicaEngine(e, []engine.Engine{&fakeEngine{}}, nil)
+	if err := New(replicas, "golden.json").Connect(); err == nil || !strings.Contains(err.Error(), "read replicas") {
+		t.Errorf("expected connecting to fail with read replicas, got %v", err)
+	}
+
+	t.Setenv(Env, "")
+	if New(replicas, "golden.json") != engine.Engine(replicas) {
+		t.Errorf("expected the engine with read replicas to be used as is")
+	}
+
+	t.Setenv(Env, "replya")
+	if err := New(e, "golden.json").Connect(); err == nil || !strings.Contains(err.Error(), Env) {
+		t.Errorf("expected connecting to fail with an invalid mode, got %v", err)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	t.Setenv(Env, "record")
+	if _, ok := Wrap("golden.json")("", &fakeEngine{}).(*Recorder); !ok {
+		t.Errorf("expected the engine to be wrapped in a recorder")
+	}
+}
+
+func TestGoldenPath(t *testing.T) {
+	t.Run("sub test", func(t *testing.T) {
+		expected := filepath.Join("testdata", "replay", "TestGoldenPath", "sub_test.json")
+		if path := GoldenPath(t); path != expected {
+			t.Errorf("expected %s, got %s", expected, path)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+// ErrUnknownRequest is returned by a Replayer for requests which were not recorded
+var ErrUnknownRequest = errors.New("request was not recorded")
+
+// Replayer serves the responses of a golden file without a query engine.
+// Each recorded interaction is served once, in the order of the recording for identical requests.
+type Replayer struct {
+	path         string
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer returns a Replayer which serves the golden file at path. The file is read when connecting.
+func NewReplayer(path string) *Replayer {
+	return &Replayer{
+		path: path,
+	}
+}
+
+func (r *Replayer) Name() string {
+	return "replay"
+}
+
+// Connect reads the golden file
+func (r *Replayer) Connect() error {
+	interactions, err := load(r.path)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = interactions
+	r.used = make([]bool, len(interactions))
+	return nil
+}
+
+func (r *Replayer) Disconnect() error {
+	return nil
+}
+
+// Unused returns the recorded interactions which were not requested, e.g. because the tested code changed
+// and the golden file should be recorded again
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+func (r *Replayer) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	return r.replay(ctx, KindDo, payload, v)
+}
+
+func (r *Replayer) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	return r.replay(ctx, KindBatch, payload, v)
+}
+
+func (r *Replayer) StartTx(ctx context.Context, opts engine.TxOptions) (string, error) {
+	var id string
+	if err := r.replay(ctx, KindStartTx, opts, &id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+func (r *Replayer) CommitTx(ctx context.Context, id string) error {
+	return r.replay(engine.WithTxID(ctx, id), KindCommitTx, nil, nil)
+}
+
+func (r *Replayer) RollbackTx(ctx context.Context, id string) error {
+	return r.replay(engine.WithTxID(ctx, id), KindRollbackTx, nil, nil)
+}
+
+// replay decodes the response of the first unused interaction matching the request into v
+func (r *Replayer) replay(ctx context.Context, kind Kind, payload interface{}, v interface{}) error {
+	var request []byte
+	if payload != nil {
+		var err error
+		request, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+	txID := engine.TxID(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.used == nil {
+		return fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
+	}
+
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Kind != kind || interaction.TxID != txID || !bytes.Equal(interaction.Request, request) {
+			continue
+		}
+		r.used[i] = true
+
+		if interaction.Error != nil {
+			return interaction.Error.Err()
+		}
+		if v == nil || interaction.Response == nil {
+			return nil
+		}
+		if err := json.Unmarshal(interaction.Response, v); err != nil {
+			return fmt.Errorf("decode recorded response: %w", err)
+		}
+		return nil
+	}
+
+	return r.unknown(kind, string(request))
+}
+
+// unknown returns an error for a request which was not recorded, including a diff to the most similar
+// recorded request
+func (r *Replayer) unknown(kind Kind, request string) error {
+	closest := ""
+	best := -1
+	for i, interaction := range r.interactions {
+		if interaction.Kind != kind {
+			continue
+		}
+		recorded := string(interaction.Request)
+		score := commonPrefix(recorded, request) + commonSuffix(recorded, request)
+		if r.used[i] {
+			// prefer requests which were not served yet, as identical requests are served once each
+			score--
+		}
+		if score > best {
+			closest, best = recorded, score
+		}
+	}
+
+	if best == -1 {
+		return fmt.Errorf("%w in %s: %s request %s", ErrUnknownRequest, r.path, kind, request)
+	}
+	if closest == request {
+		return fmt.Errorf("%w in %s: %s request %s was sent more often than recorded, or in another transaction", ErrUnknownRequest, r.path, kind, request)
+	}
+	return fmt.Errorf("%w in %s: %s request differs from the closest recorded request:\n%s", ErrUnknownRequest, r.path, kind, diff(closest, request))
+}
+
+// diffContext is the number of characters shown around the first difference
+const diffContext = 60
+
+// diff shows an excerpt of both requests around their first difference, which is marked with a caret
+func diff(recorded, actual string) string {
+	start := commonPrefix(recorded, actual)
+	from := start - diffContext
+	prefix := "..."
+	if from <= 0 {
+		from = 0
+		prefix = ""
+	}
+	excerpt := func(s string) string {
+		to := start + diffContext
+		if to >= len(s) {
+			return prefix + s[from:]
+		}
+		return prefix + s[from:to] + "..."
+	}
+
+	var b strings.Builder
+	b.WriteString("- recorded: " + excerpt(recorded) + "\n")
+	b.WriteString("+ actual:   " + excerpt(actual) + "\n")
+	b.WriteString(strings.Repeat(" ", len("+ actual:   ")+len(prefix)+start-from) + "^")
+	return b.String()
+}
+
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+func commonSuffix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[len(a)-1-n] == b[len(b)-1-n] {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,228 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// Picker selects the replica engine a read query is sent to. replicas always contains at least one engine.
+type Picker func(replicas []Engine) Engine
+
+// RoundRobin returns a Picker which cycles through the replicas
+func RoundRobin() Picker {
+	var next uint64
+	return func(replicas []Engine) Engine {
+		i := atomic.AddUint64(&next, 1) - 1
+		return replicas[i%uint64(len(replicas))]
+	}
+}
+
+// ReadRouter is implemented by engines which send read queries to a different engine than writes
+type ReadRouter interface {
+	// ReadReplica returns the engine the next read query should be sent to, or nil to use the engine itself
+	ReadReplica() Engine
+}
+
+// NewReplicaEngine returns an engine which sends read queries to the replicas and everything else to the primary.
+// If picker is nil, replicas are picked round-robin.
+func NewReplicaEngine(primary Engine, replicas []Engine, picker Picker) *ReplicaEngine {
+	if picker == nil {
+		picker = RoundRobin()
+	}
+	return &ReplicaEngine{
+		Primary:  primary,
+		Replicas: replicas,
+		picker:   picker,
+	}
+}
+
+// ReplicaEngine wraps a primary engine and a pool of read replica engines.
+// Do and Batch always use the primary; read queries are routed to a replica via ReadReplica.
+type ReplicaEngine struct {
+	Primary  Engine
+	Replicas []Engine
+
+	picker Picker
+
+	readyMu sync.Mutex
+	ready   chan struct{}
+	// readyOf contains the ready channels of the members which ready waits for
+	readyOf []<-chan struct{}
+}
+
+// Connect connects the primary and all replica engines
+func (e *ReplicaEngine) Connect() error {
+	return e.ConnectContext(context.Background())
+}
+
+// ConnectContext connects the primary and all replica engines, or stops when ctx is done
+func (e *ReplicaEngine) ConnectContext(ctx context.Context) error {
+	if err := Connect(ctx, e.Primary); err != nil {
+		return fmt.Errorf("connect primary: %w", err)
+	}
+	for i, replica := range e.Replicas {
+		if err := Connect(ctx, replica); err != nil {
+			return fmt.Errorf("connect replica %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Disconnect disconnects the primary and all replica engines, and returns the first error if any
+func (e *ReplicaEngine) Disconnect() error {
+	var first error
+	if err := e.Primary.Disconnect(); err != nil {
+		first = fmt.Errorf("disconnect primary: %w", err)
+	}
+	for i, replica := range e.Replicas {
+		if err := replica.Disconnect(); err != nil && first == nil {
+			first = fmt.Errorf("disconnect replica %d: %w", i, err)
+		}
+	}
+	return first
+}
+
+// DisconnectContext gracefully disconnects the primary and all replica engines, and returns the first error if any
+func (e *ReplicaEngine) DisconnectContext(ctx context.Context) error {
+	disconnect := func(engine Engine) error {
+		if g, ok := engine.(GracefulDisconnecter); ok {
+			return g.DisconnectContext(ctx)
+		}
+		return engine.Disconnect()
+	}
+
+	var first error
+	if err := disconnect(e.Primary); err != nil {
+		first = fmt.Errorf("disconnect primary: %w", err)
+	}
+	for i, replica := range e.Replicas {
+		if err := disconnect(replica); err != nil && first == nil {
+			first = fmt.Errorf("disconnect replica %d: %w", i, err)
+		}
+	}
+	return first
+}
+
+func (e *ReplicaEngine) Do(ctx context.Context, payload interface{}, into interface{}) error {
+	return e.Primary.Do(ctx, payload, into)
+}
+
+func (e *ReplicaEngine) Batch(ctx context.Context, payload interface{}, into interface{}) error {
+	return e.Primary.Batch(ctx, payload, into)
+}
+
+func (e *ReplicaEngine) Name() string {
+	return e.Primary.Name()
+}
+
+// ReadReplica picks the replica for the next read query
+func (e *ReplicaEngine) ReadReplica() Engine {
+	if len(e.Replicas) == 0 {
+		return nil
+	}
+	return e.picker(e.Replicas)
+}
+
+// StartTx starts an interactive transaction on the primary
+func (e *ReplicaEngine) StartTx(ctx context.Context, opts TxOptions) (string, error) {
+	t, ok := e.Primary.(Transactional)
+	if !ok {
+		return "", fmt.Errorf("engine %s does not support interactive transactions", e.Primary.Name())
+	}
+	return t.StartTx(ctx, opts)
+}
+
+// CommitTx commits an interactive transaction on the primary
+func (e *ReplicaEngine) CommitTx(ctx context.Context, id string) error {
+	t, ok := e.Primary.(Transactional)
+	if !ok {
+		return fmt.Errorf("engine %s does not support interactive transactions", e.Primary.Name())
+	}
+	return t.CommitTx(ctx, id)
+}
+
+// RollbackTx rolls back an interactive transaction on the primary
+func (e *ReplicaEngine) RollbackTx(ctx context.Context, id string) error {
+	t, ok := e.Primary.(Transactional)
+	if !ok {
+		return fmt.Errorf("engine %s does not support interactive transactions", e.Primary.Name())
+	}
+	return t.RollbackTx(ctx, id)
+}
+
+// Health returns the health of the primary and fails if the primary or any replica is unhealthy
+func (e *ReplicaEngine) Health(ctx context.Context) (*Health, error) {
+	h := &Health{
+		Name: e.Primary.Name(),
+	}
+	if checker, ok := e.Primary.(HealthChecker); ok {
+		var err error
+		h, err = checker.Health(ctx)
+		if err != nil {
+			return h, fmt.Errorf("primary: %w", err)
+		}
+	}
+	for i, replica := range e.Replicas {
+		if checker, ok := replica.(HealthChecker); ok {
+			if _, err := checker.Health(ctx); err != nil {
+				return h, fmt.Errorf("replica %d: %w", i, err)
+			}
+		}
+	}
+	return h, nil
+}
+
+// Ready returns a channel which is closed when the primary and all replicas are ready. Like the ready channels of
+// the members, it is replaced after a member crashed or was disconnected.
+func (e *ReplicaEngine) Ready() <-chan struct{} {
+	var members []<-chan struct{}
+	for _, member := range append([]Engine{e.Primary}, e.Replicas...) {
+		if checker, ok := member.(HealthChecker); ok {
+			members = append(members, checker.Ready())
+		}
+	}
+
+	e.readyMu.Lock()
+	defer e.readyMu.Unlock()
+	if e.ready != nil && sameChannels(e.readyOf, members) {
+		return e.ready
+	}
+	ready := make(chan struct{})
+	go func() {
+		for _, c := range members {
+			<-c
+		}
+		close(ready)
+	}()
+	e.ready, e.readyOf = ready, members
+	return ready
+}
+
+func sameChannels(a, b []<-chan struct{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Logger returns the logger of the primary
+func (e *ReplicaEngine) Logger() *slog.Logger {
+	return Logger(e.Primary)
+}
+
+// OnQuery registers fn for the database queries of the primary and all replicas
+func (e *ReplicaEngine) OnQuery(fn func(QueryEvent)) {
+	for _, member := range append([]Engine{e.Primary}, e.Replicas...) {
+		if l, ok := member.(QueryLogger); ok {
+			l.OnQuery(fn)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type namedEngine struct {
+	Engine
+	name  string
+	calls *[]string
+	err   error
+}
+
+func (e namedEngine) Connect() error {
+	*e.calls = append(*e.calls, "connect "+e.name)
+	return e.err
+}
+
+func (e namedEngine) Disconnect() error {
+	*e.calls = append(*e.calls, "disconnect "+e.name)
+	return e.err
+}
+
+func (e namedEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	*e.calls = append(*e.calls, "do "+e.name)
+	return nil
+}
+
+func (e namedEngine) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	*e.calls = append(*e.calls, "batch "+e.name)
+	return nil
+}
+
+func (e namedEngine) Name() string {
+	return e.name
+}
+
+func TestReplicaEngine(t *testing.T) {
+	var calls []string
+	primary := namedEngine{name: "primary", calls: &calls}
+	a := namedEngine{name: "a", calls: &calls}
+	b := namedEngine{name: "b", calls: &calls}
+
+	e := NewReplicaEngine(primary, []Engine{a, b}, nil)
+
+	if err := e.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Do(context.Background(), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Batch(context.Background(), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var picked []string
+	for i := 0; i < 3; i++ {
+		picked = append(picked, e.ReadReplica().Name())
+	}
+
+	if err := e.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantCalls := []string{
+		"connect primary", "connect a", "connect b",
+		"do primary", "batch primary",
+		"disconnect primary", "disconnect a", "disconnect b",
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %v, want %v", calls, wantCalls)
+	}
+	if want := []string{"a", "b", "a"}; !reflect.DeepEqual(picked, want) {
+		t.Errorf("picked = %v, want %v", picked, want)
+	}
+	if e.Name() != "primary" {
+		t.Errorf("Name() = %s, want primary", e.Name())
+	}
+}
+
+func TestReplicaEngine_picker(t *testing.T) {
+	var calls []string
+	a := namedEngine{name: "a", calls: &calls}
+	b := namedEngine{name: "b", calls: &calls}
+
+	last := func(replicas []Engine) Engine {
+		return replicas[len(replicas)-1]
+	}
+	e := NewReplicaEngine(namedEngine{name: "primary", calls: &calls}, []Engine{a, b}, last)
+	if name := e.ReadReplica().Name(); name != "b" {
+		t.Errorf("ReadReplica() = %s, want b", name)
+	}
+
+	empty := NewReplicaEngine(namedEngine{name: "primary", calls: &calls}, nil, nil)
+	if r := empty.ReadReplica(); r != nil {
+		t.Errorf("ReadReplica() = %v, want nil without replicas", r)
+	}
+}
+
+func TestReplicaEngine_Disconnect(t *testing.T) {
+	var calls []string
+	errFailed := errors.New("failed")
+	e := NewReplicaEngine(
+		namedEngine{name: "primary", calls: &calls},
+		[]Engine{namedEngine{name: "a", calls: &calls, err: errFailed}, namedEngine{name: "b", calls: &calls}},
+		nil,
+	)
+
+	err := e.Disconnect()
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected disconnect error, got %v", err)
+	}
+	if want := []string{"disconnect primary", "disconnect a", "disconnect b"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestReplicaEngine_Ready(t *testing.T) {
+	primary, replica := NewQueryEngine("", false), NewQueryEngine("", false)
+	e := NewReplicaEngine(primary, []Engine{replica}, nil)
+
+	isReady := func() bool {
+		select {
+		case <-e.Ready():
+			return true
+		case <-time.After(50 * time.Millisecond):
+			return false
+		}
+	}
+
+	primary.mu.Lock()
+	primary.setReady()
+	primary.mu.Unlock()
+	if isReady() {
+		t.Fatalf("must not be ready before all replicas are ready")
+	}
+	replica.mu.Lock()
+	replica.setReady()
+	replica.mu.Unlock()
+	if !isReady() {
+		t.Fatalf("must be ready once all engines are ready")
+	}
+
+	// e.g. after a crash of the replica
+	replica.mu.Lock()
+	replica.setNotReady()
+	replica.mu.Unlock()
+	if isReady() {
+		t.Fatalf("must not be ready while a replica is down")
+	}
+	replica.mu.Lock()
+	replica.setReady()
+	replica.mu.Unlock()
+	if !isReady() {
+		t.Fatalf("must be ready once the replica is ready again")
+	}
+}
@@ -4,11 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
-
-	"github.com/vnsoft2014/prisma-client-go/logger"
-	"github.com/vnsoft2014/prisma-client-go/runtime/types"
 )
 
 var internalUpdateNotFoundMessage = "Error occurred during query execution: InterpretationError(\"Error for binding '0'\", Some(QueryGraphBuilderError(RecordNotFound(\"Record to update not found.\"))))"
@@ -23,8 +21,10 @@ func (e *QueryEngine) Do(ctx context.Context, payload interface{}, v interface{}
 		return fmt.Errorf("request failed: %w", err)
 	}
 
-	logger.Debug.Printf("[timing] query engine request took %s", time.Since(startReq))
-	logger.Debug.Printf("[timing] query engine response %s", body)
+	log := e.opts.log()
+	if log.Enabled(ctx, slog.LevelDebug) {
+		log.DebugContext(ctx, "query engine request done", "duration", time.Since(startReq), "response", string(body))
+	}
 
 	startParse := time.Now()
 
@@ -35,11 +35,7 @@ func (e *QueryEngine) Do(ctx context.Context, payload interface{}, v interface{}
 
 	if len(response.Errors) > 0 {
 		first := response.Errors[0]
-		if first.RawMessage() == internalUpdateNotFoundMessage ||
-			first.RawMessage() == internalDeleteNotFoundMessage {
-			return types.ErrNotFound
-		}
-		return fmt.Errorf("pql error: %s", first.RawMessage())
+		return first.Err()
 	}
 
 	response.Data.Result, err = transformResponse(response.Data.Result)
@@ -51,7 +47,7 @@ func (e *QueryEngine) Do(ctx context.Context, payload interface{}, v interface{}
 		return fmt.Errorf("json data result unmarshal: %w", err)
 	}
 
-	logger.Debug.Printf("[timing] request unmarshaling took %s", time.Since(startParse))
+	log.DebugContext(ctx, "request unmarshal done", "duration", time.Since(startParse))
 
 	return nil
 }
@@ -76,22 +72,65 @@ func (e *QueryEngine) Batch(ctx context.Context, payload interface{}, v interfac
 }
 
 func (e *QueryEngine) Request(ctx context.Context, method string, path string, payload interface{}) ([]byte, error) {
-	if e.disconnected {
-		logger.Info.Printf("A query was executed after Disconnect() was called. Make sure to not send any queries after disconnecting the client.")
+	e.mu.Lock()
+	disconnected, crashErr, url, exited := e.disconnected, e.crashErr, e.url, e.exited
+	if !disconnected && crashErr == nil {
+		// track the request so that Disconnect waits for it
+		e.active++
+	}
+	e.mu.Unlock()
+
+	if disconnected {
+		e.opts.log().Warn("A query was executed after Disconnect() was called. Make sure to not send any queries after disconnecting the client.")
 		return nil, fmt.Errorf("client is disconnected")
 	}
 
+	if crashErr != nil {
+		return nil, crashErr
+	}
+
+	defer e.done()
+
+	body, err := e.send(ctx, url, method, path, payload)
+	if err != nil && exited != nil {
+		// the request may have failed because the engine just crashed, before the supervisor noticed it
+		select {
+		case <-exited:
+			if crashErr := e.crashed(); crashErr != nil {
+				return nil, fmt.Errorf("%w (%s)", crashErr, err)
+			}
+		default:
+		}
+	}
+	return body, err
+}
+
+// done marks a request as finished and notifies Disconnect when the last in-flight request finished
+func (e *QueryEngine) done() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.active--
+	if e.active == 0 && e.drained != nil {
+		close(e.drained)
+		e.drained = nil
+	}
+}
+
+// send sends a request to the engine at the given url
+func (e *QueryEngine) send(ctx context.Context, url string, method string, path string, payload interface{}) ([]byte, error) {
 	requestBody, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("payload marshal: %w", err)
 	}
 
-	// TODO use specific log level
-	if logger.Enabled {
-		logger.Debug.Printf("prisma engine payload: `%s`", requestBody)
-	}
+	ctx, cancel, wrap := withRequestTimeout(ctx, e.opts.requestTimeout)
+	defer cancel()
 
-	return request(ctx, e.http, method, e.url+path, requestBody, func(req *http.Request) {
+	body, err := request(ctx, e.opts.log(), e.http, method, url+path, requestBody, func(req *http.Request) {
 		req.Header.Set("content-type", "application/json")
+		if id := TxID(ctx); id != "" {
+			req.Header.Set("X-transaction-id", id)
+		}
 	})
+	return body, wrap(err)
 }
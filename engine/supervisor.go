@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// ErrEngineCrashed is returned for requests sent while the query engine process is not running
+// because it exited unexpectedly, e.g. after running out of memory
+var ErrEngineCrashed = errors.New("query engine crashed")
+
+// HealthState describes a change of the state of the query engine process
+type HealthState string
+
+const (
+	// HealthCrashed means the engine process exited unexpectedly
+	HealthCrashed HealthState = "crashed"
+	// HealthRestarting means a restart attempt failed and the engine will be restarted again after a backoff
+	HealthRestarting HealthState = "restarting"
+	// HealthRestarted means the engine was restarted successfully and accepts requests again
+	HealthRestarted HealthState = "restarted"
+	// HealthFailed means the engine stays down, either because restarting is disabled or all attempts failed
+	HealthFailed HealthState = "failed"
+)
+
+// HealthEvent is passed to the health callback when the state of the engine process changes
+type HealthEvent struct {
+	State HealthState
+
+	// Err contains the crash error, or the error of the last restart attempt
+	Err error
+
+	// Attempt is the number of the restart attempt since the last crash
+	Attempt int
+
+	// Restarts is the number of successful restarts since connecting
+	Restarts int
+}
+
+// RestartPolicy configures how a crashed engine is restarted
+type RestartPolicy struct {
+	// MaxAttempts is the maximum number of restart attempts after a crash; 0 means unlimited
+	MaxAttempts int
+
+	// MinBackoff is the delay before the first restart attempt, which doubles after every failed attempt.
+	// Defaults to 100ms.
+	MinBackoff time.Duration
+
+	// MaxBackoff is the maximum delay between restart attempts. Defaults to 10s.
+	MaxBackoff time.Duration
+}
+
+const (
+	defaultMinBackoff = 100 * time.Millisecond
+	defaultMaxBackoff = 10 * time.Second
+)
+
+// start makes the given running engine process the current one, marks the engine as ready and supervises the
+// process. If the engine was disconnected in the meantime, the process is killed instead and false is returned.
+func (e *QueryEngine) start(file string, cmd *exec.Cmd, url string) bool {
+	exited := make(chan struct{})
+
+	e.mu.Lock()
+	if e.disconnected {
+		e.mu.Unlock()
+		kill(cmd)
+		return false
+	}
+	e.cmd = cmd
+	e.url = url
+	e.exited = exited
+	e.exitErr = nil
+	e.crashErr = nil
+	e.startedAt = time.Now()
+	e.setReady()
+	e.mu.Unlock()
+
+	go e.supervise(file, cmd, exited)
+	return true
+}
+
+// spawned is called with every engine process which was spawned by a restart attempt before it is started.
+// It is only set by tests.
+var spawned func(cmd *exec.Cmd)
+
+// supervise waits for the engine process to exit. If it exits without Disconnect being called,
+// requests fail with ErrEngineCrashed and the engine is restarted if a restart policy is set.
+func (e *QueryEngine) supervise(file string, cmd *exec.Cmd, exited chan struct{}) {
+	err := cmd.Wait()
+
+	e.mu.Lock()
+	e.exitErr = err
+	disconnected := e.disconnected
+	if !disconnected {
+		e.crashErr = fmt.Errorf("%w: %s", ErrEngineCrashed, exitReason(err))
+		e.lastErr = e.crashErr
+		e.setNotReady()
+	}
+	crashErr := e.crashErr
+	restarts := e.restarts
+	stop := e.stop
+	close(exited)
+	e.mu.Unlock()
+
+	if disconnected {
+		return
+	}
+
+	e.opts.log().Error("query engine crashed", "error", crashErr, "restarts", restarts)
+	e.health(HealthEvent{
+		State:    HealthCrashed,
+		Err:      crashErr,
+		Restarts: restarts,
+	})
+
+	if e.opts.restart == nil {
+		e.health(HealthEvent{
+			State:    HealthFailed,
+			Err:      crashErr,
+			Restarts: restarts,
+		})
+		return
+	}
+
+	e.restart(file, stop)
+}
+
+// restart starts a new engine process with backoff until it succeeds, the restart attempts are exhausted
+// or the engine is disconnected
+func (e *QueryEngine) restart(file string, stop chan struct{}) {
+	policy := *e.opts.restart
+	backoff := policy.MinBackoff
+	if backoff <= 0 {
+		backoff = defaultMinBackoff
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	var lastErr error
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		select {
+		case <-stop:
+			return
+		case <-time.After(backoff):
+		}
+
+		e.opts.log().Debug("restarting query engine", "attempt", attempt)
+
+		cmd, url, err := e.spawn(context.Background(), file)
+		if err != nil {
+			lastErr = fmt.Errorf("restart attempt %d: %w", attempt, err)
+			e.mu.Lock()
+			e.lastErr = lastErr
+			e.mu.Unlock()
+			e.opts.log().Warn("could not restart query engine", "error", err, "attempt", attempt)
+			e.health(HealthEvent{
+				State:    HealthRestarting,
+				Err:      lastErr,
+				Attempt:  attempt,
+				Restarts: e.restartCount(),
+			})
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			continue
+		}
+
+		if spawned != nil {
+			spawned(cmd)
+		}
+
+		if !e.start(file, cmd, url) {
+			return
+		}
+
+		e.mu.Lock()
+		e.restarts++
+		restarts := e.restarts
+		e.mu.Unlock()
+
+		e.opts.log().Info("query engine restarted", "url", url, "attempt", attempt)
+		e.health(HealthEvent{
+			State:    HealthRestarted,
+			Attempt:  attempt,
+			Restarts: restarts,
+		})
+		return
+	}
+
+	e.health(HealthEvent{
+		State:    HealthFailed,
+		Err:      lastErr,
+		Attempt:  policy.MaxAttempts,
+		Restarts: e.restartCount(),
+	})
+}
+
+// crashed returns the crash error if the engine process is currently not running because it crashed
+func (e *QueryEngine) crashed() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.crashErr
+}
+
+func (e *QueryEngine) restartCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.restarts
+}
+
+func (e *QueryEngine) health(event HealthEvent) {
+	if e.opts.healthCallback != nil {
+		e.opts.healthCallback(event)
+	}
+}
+
+// exitReason describes why the engine process exited
+func exitReason(err error) string {
+	if err == nil {
+		return "process exited"
+	}
+	return err.Error()
+}
@@ -0,0 +1,254 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/vnsoft2014/prisma-client-go/binaries"
+)
+
+// fakeEngineEnv makes the test binary act as a query engine, see fakeEngine
+const fakeEngineEnv = "PRISMA_CLIENT_GO_TEST_FAKE_ENGINE"
+
+// fakeEngineNotReadyEnv makes the readiness check of the fake engine fail
+const fakeEngineNotReadyEnv = "PRISMA_CLIENT_GO_TEST_FAKE_ENGINE_NOT_READY"
+
+// fakeEngineIgnoreInterruptEnv makes the fake engine ignore interrupt signals, so it has to be killed
+const fakeEngineIgnoreInterruptEnv = "PRISMA_CLIENT_GO_TEST_FAKE_ENGINE_IGNORE_INTERRUPT"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeEngineEnv) != "" {
+		fakeEngine()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// fakeEngine serves the readiness check and answers every query with its process id.
+// Queries containing "sleep" are answered after a delay. Every query is logged like a database query.
+// It exits with an error when /exit is requested, to simulate a crash.
+func fakeEngine() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "--version" {
+		fmt.Printf("query-engine %s\n", binaries.EngineVersion)
+		return
+	}
+
+	if os.Getenv(fakeEngineIgnoreInterruptEnv) != "" {
+		signal.Ignore(os.Interrupt)
+	}
+
+	var port, socket string
+	for i, arg := range args {
+		if arg == "-p" && i+1 < len(args) {
+			port = args[i+1]
+		}
+		if arg == "--unix-path" && i+1 < len(args) {
+			socket = args[i+1]
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if os.Getenv(fakeEngineNotReadyEnv) != "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+	mux.HandleFunc("/exit", func(w http.ResponseWriter, r *http.Request) {
+		os.Exit(1)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), "sleep") {
+			time.Sleep(300 * time.Millisecond)
+		}
+		fmt.Printf(`{"timestamp":"2022-01-01T00:00:00Z","level":"INFO","fields":{"query":"SELECT 1","params":"[]","duration_ms":2},"target":"quaint::connector::metrics"}` + "\n")
+		_, _ = fmt.Fprintf(w, `{"data":{"result":{"pid":%d}}}`, os.Getpid())
+	})
+	network, addr := "tcp", "localhost:"+port
+	if socket != "" {
+		network, addr = "unix", socket
+	}
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		os.Exit(2)
+	}
+	if err := http.Serve(l, mux); err != nil {
+		os.Exit(2)
+	}
+}
+
+type pidResult struct {
+	PID int `json:"pid"`
+}
+
+func newFakeEngine(t *testing.T, opts ...Option) (*QueryEngine, chan HealthEvent) {
+	t.Setenv(fakeEngineEnv, "1")
+
+	events := make(chan HealthEvent, 10)
+	opts = append(opts, WithBinaryPath(os.Args[0]), WithHealthCallback(func(event HealthEvent) {
+		events <- event
+	}))
+	e := NewQueryEngine("", false, opts...)
+	if err := e.Connect(); err != nil {
+		t.Fatalf("could not connect: %s", err)
+	}
+	return e, events
+}
+
+func crash(t *testing.T, e *QueryEngine) {
+	e.mu.Lock()
+	url := e.url
+	e.mu.Unlock()
+
+	// the fake engine exits without sending a response
+	_, _ = http.Get(url + "/exit")
+}
+
+func waitFor(t *testing.T, events chan HealthEvent, state HealthState) HealthEvent {
+	for {
+		select {
+		case event := <-events:
+			if event.State == state {
+				return event
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for health state %s", state)
+		}
+	}
+}
+
+func TestQueryEngine_crash(t *testing.T) {
+	e, events := newFakeEngine(t)
+
+	var before pidResult
+	if err := e.Do(context.Background(), map[string]interface{}{}, &before); err != nil {
+		t.Fatal(err)
+	}
+
+	crash(t, e)
+
+	event := waitFor(t, events, HealthCrashed)
+	if !errors.Is(event.Err, ErrEngineCrashed) {
+		t.Errorf("expected crash event error to be ErrEngineCrashed, got %v", event.Err)
+	}
+	waitFor(t, events, HealthFailed)
+
+	var after pidResult
+	err := e.Do(context.Background(), map[string]interface{}{}, &after)
+	if !errors.Is(err, ErrEngineCrashed) {
+		t.Fatalf("expected ErrEngineCrashed, got %v", err)
+	}
+
+	if err := e.Disconnect(); err != nil {
+		t.Fatalf("could not disconnect crashed engine: %s", err)
+	}
+}
+
+func TestQueryEngine_restart(t *testing.T) {
+	e, events := newFakeEngine(t, WithRestart(RestartPolicy{
+		MinBackoff: 10 * time.Millisecond,
+	}))
+
+	var before pidResult
+	if err := e.Do(context.Background(), map[string]interface{}{}, &before); err != nil {
+		t.Fatal(err)
+	}
+
+	crash(t, e)
+
+	waitFor(t, events, HealthCrashed)
+	event := waitFor(t, events, HealthRestarted)
+	if event.Restarts != 1 {
+		t.Errorf("expected 1 restart, got %d", event.Restarts)
+	}
+	select {
+	case <-e.Ready():
+	default:
+		t.Errorf("engine must be ready after a restart")
+	}
+
+	var after pidResult
+	if err := e.Do(context.Background(), map[string]interface{}{}, &after); err != nil {
+		t.Fatalf("request after restart failed: %s", err)
+	}
+	if after.PID == before.PID {
+		t.Errorf("expected a new engine process, got the same pid %d", after.PID)
+	}
+
+	if err := e.Disconnect(); err != nil {
+		t.Fatalf("could not disconnect: %s", err)
+	}
+	select {
+	case <-e.Ready():
+		t.Errorf("engine must not be ready after disconnecting")
+	default:
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("unexpected health event after disconnect: %+v", event)
+	default:
+	}
+}
+
+func TestQueryEngine_disconnectDuringRestart(t *testing.T) {
+	e, events := newFakeEngine(t, WithRestart(RestartPolicy{
+		MinBackoff: 10 * time.Millisecond,
+	}))
+
+	restarted := make(chan *exec.Cmd, 1)
+	spawned = func(cmd *exec.Cmd) {
+		// disconnect after the new process was spawned, but before it is started
+		if err := e.Disconnect(); err != nil {
+			t.Errorf("could not disconnect: %s", err)
+		}
+		restarted <- cmd
+	}
+	t.Cleanup(func() {
+		spawned = nil
+	})
+
+	crash(t, e)
+	waitFor(t, events, HealthCrashed)
+
+	var cmd *exec.Cmd
+	select {
+	case cmd = <-restarted:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the restart")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for cmd.Process.Signal(syscall.Signal(0)) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("engine process spawned during disconnect is still running")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case <-e.Ready():
+		t.Errorf("engine must not be ready after disconnecting")
+	default:
+	}
+
+	select {
+	case event := <-events:
+		t.Errorf("unexpected health event after disconnect: %+v", event)
+	default:
+	}
+}
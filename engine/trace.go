@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+// RequestTrace is notified about http requests sent to the query engine, e.g. to record timings
+type RequestTrace struct {
+	// RoundTrip is called after a request finished. elapsed is the time the query engine reported
+	// to spend on the request, or 0 if the engine didn't report it.
+	RoundTrip func(start time.Time, end time.Time, elapsed time.Duration, err error)
+}
+
+type requestTraceKey struct{}
+
+// WithRequestTrace returns a context which notifies trace about all requests sent with it
+func WithRequestTrace(ctx context.Context, trace *RequestTrace) context.Context {
+	return context.WithValue(ctx, requestTraceKey{}, trace)
+}
+
+// RequestTraceFromContext returns the trace of a context, or nil if there is none.
+// Custom engines can use it to report their requests.
+func RequestTraceFromContext(ctx context.Context) *RequestTrace {
+	trace, _ := ctx.Value(requestTraceKey{}).(*RequestTrace)
+	if trace == nil || trace.RoundTrip == nil {
+		return nil
+	}
+	return trace
+}
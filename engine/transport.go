@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// TransportOptions tunes the http transport used to send requests to the engine.
+// Zero values use the defaults of DefaultTransportOptions.
+type TransportOptions struct {
+	// MaxIdleConns is the maximum number of idle connections kept open
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept open to the engine.
+	// net/http keeps 2 by default, so concurrent queries open and close connections all the time,
+	// which can exhaust ephemeral ports under load.
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost limits the number of connections to the engine, including connections in use.
+	// Zero means no limit.
+	MaxConnsPerHost int
+
+	// IdleConnTimeout is the maximum amount of time an idle connection is kept open
+	IdleConnTimeout time.Duration
+
+	// KeepAlive is the interval of tcp keep-alive probes
+	KeepAlive time.Duration
+
+	// DialTimeout is the maximum amount of time to wait for a connection to the engine
+	DialTimeout time.Duration
+
+	// HTTP2 sends requests via HTTP/2 cleartext (h2c) with prior knowledge instead of HTTP/1.1.
+	// Only enable it if the engine supports h2c; requests to a data proxy use HTTP/2 over TLS regardless.
+	// It requires Go 1.24 or later and is ignored when built with older versions.
+	HTTP2 bool
+}
+
+// DefaultTransportOptions are the transport options used when no options or zero values are given
+var DefaultTransportOptions = TransportOptions{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 100,
+	IdleConnTimeout:     90 * time.Second,
+	KeepAlive:           30 * time.Second,
+	DialTimeout:         10 * time.Second,
+}
+
+// withDefaults returns the options with zero values replaced by the defaults
+func (o TransportOptions) withDefaults() TransportOptions {
+	d := DefaultTransportOptions
+	if o.MaxIdleConns == 0 {
+		o.MaxIdleConns = d.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost == 0 {
+		o.MaxIdleConnsPerHost = d.MaxIdleConnsPerHost
+	}
+	if o.MaxConnsPerHost == 0 {
+		o.MaxConnsPerHost = d.MaxConnsPerHost
+	}
+	if o.IdleConnTimeout == 0 {
+		o.IdleConnTimeout = d.IdleConnTimeout
+	}
+	if o.KeepAlive == 0 {
+		o.KeepAlive = d.KeepAlive
+	}
+	if o.DialTimeout == 0 {
+		o.DialTimeout = d.DialTimeout
+	}
+	return o
+}
+
+// newTransport returns a transport with the given options. If socket is not empty, all connections are made to
+// the unix domain socket at that path, regardless of the request url.
+func newTransport(o TransportOptions, socket string) *http.Transport {
+	o = o.withDefaults()
+
+	dialer := &net.Dialer{
+		Timeout:   o.DialTimeout,
+		KeepAlive: o.KeepAlive,
+	}
+
+	t := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          o.MaxIdleConns,
+		MaxIdleConnsPerHost:   o.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       o.MaxConnsPerHost,
+		IdleConnTimeout:       o.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if socket != "" {
+		// requests to the engine never go through a proxy
+		t.Proxy = nil
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+	}
+
+	if o.HTTP2 {
+		enableH2C(t)
+	}
+
+	return t
+}
+
+// socketCount makes the socket paths of multiple engines within the same process unique
+var socketCount uint64
+
+// socketPath returns a unique path for the unix domain socket of an engine in the given directory
+func socketPath(dir string) string {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	n := atomic.AddUint64(&socketCount, 1)
+	return filepath.Join(dir, fmt.Sprintf("prisma-query-engine-%d-%d.sock", os.Getpid(), n))
+}
@@ -0,0 +1,13 @@
+//go:build go1.24
+
+package engine
+
+import "net/http"
+
+// enableH2C makes the transport send requests via HTTP/2 cleartext with prior knowledge
+func enableH2C(t *http.Transport) {
+	var protocols http.Protocols
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	t.Protocols = &protocols
+}
@@ -0,0 +1,8 @@
+//go:build !go1.24
+
+package engine
+
+import "net/http"
+
+// enableH2C does nothing, as net/http supports HTTP/2 cleartext only since Go 1.24; requests use HTTP/1.1
+func enableH2C(t *http.Transport) {}
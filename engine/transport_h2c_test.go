@@ -0,0 +1,34 @@
+//go:build go1.24
+
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewTransport_HTTP2(t *testing.T) {
+	if tr := newTransport(TransportOptions{}, ""); tr.Protocols != nil {
+		t.Errorf("expected default protocols without HTTP2")
+	}
+
+	tr := newTransport(TransportOptions{HTTP2: true}, "")
+	if tr.Protocols == nil || !tr.Protocols.UnencryptedHTTP2() {
+		t.Errorf("expected unencrypted HTTP/2 to be enabled")
+	}
+}
+
+func BenchmarkQueryEngine_Do_h2c(b *testing.B) {
+	h2c := httptest.NewUnstartedServer(stubEngine())
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	h2c.Config.Protocols = &protocols
+	h2c.Start()
+	defer h2c.Close()
+
+	e := NewQueryEngine("", false, WithTransport(TransportOptions{HTTP2: true}))
+	e.url = h2c.URL
+	benchmarkDo(b, e)
+}
@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTransport(t *testing.T) {
+	tr := newTransport(TransportOptions{
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     time.Second,
+	}, "")
+
+	if tr.MaxIdleConnsPerHost != 10 || tr.IdleConnTimeout != time.Second {
+		t.Errorf("expected options to be applied, got %d and %s", tr.MaxIdleConnsPerHost, tr.IdleConnTimeout)
+	}
+	if tr.MaxIdleConns != DefaultTransportOptions.MaxIdleConns {
+		t.Errorf("expected default max idle conns, got %d", tr.MaxIdleConns)
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	dir := t.TempDir()
+	first, second := socketPath(dir), socketPath(dir)
+	if first == second {
+		t.Errorf("expected unique socket paths, got %s twice", first)
+	}
+	if filepath.Dir(first) != dir {
+		t.Errorf("expected socket in %s, got %s", dir, first)
+	}
+}
+
+func TestQueryEngine_unixSocket(t *testing.T) {
+	dir := t.TempDir()
+	e, _ := newFakeEngine(t, WithUnixSocket(dir))
+
+	if !strings.HasPrefix(e.socket, dir) {
+		t.Fatalf("expected socket in %s, got %s", dir, e.socket)
+	}
+
+	var result pidResult
+	if err := e.Do(context.Background(), map[string]interface{}{}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.PID == 0 {
+		t.Fatalf("expected a response from the engine")
+	}
+
+	if err := e.Disconnect(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(e.socket); !os.IsNotExist(err) {
+		t.Errorf("expected socket to be removed, got %v", err)
+	}
+}
+
+func TestQueryEngine_unixSocket_httpClient(t *testing.T) {
+	e := NewQueryEngine("", false, WithUnixSocket(""), WithHTTPClient(&http.Client{}))
+	if err := e.Connect(); err == nil || !strings.Contains(err.Error(), "unix socket") {
+		t.Fatalf("expected unix socket error, got %v", err)
+	}
+}
+
+func stubEngine() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"result":{"id":"123","name":"stub"}}}`))
+	})
+}
+
+func benchmarkDo(b *testing.B, e *QueryEngine) {
+	payload := GQLRequest{Query: "query { result: findManyUser { id name } }", Variables: map[string]interface{}{}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var v map[string]interface{}
+			if err := e.Do(context.Background(), payload, &v); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkQueryEngine_Do compares the transports against a stub engine, e.g. via
+// go test -bench QueryEngine_Do -cpu 32 ./engine
+func BenchmarkQueryEngine_Do(b *testing.B) {
+	server := httptest.NewServer(stubEngine())
+	defer server.Close()
+
+	b.Run("default http client", func(b *testing.B) {
+		e := NewQueryEngine("", false, WithHTTPClient(&http.Client{}))
+		e.url = server.URL
+		benchmarkDo(b, e)
+	})
+
+	b.Run("tuned transport", func(b *testing.B) {
+		e := NewQueryEngine("", false)
+		e.url = server.URL
+		benchmarkDo(b, e)
+	})
+
+	b.Run("unix socket", func(b *testing.B) {
+		e := NewQueryEngine("", false, WithUnixSocket(b.TempDir()))
+
+		l, err := net.Listen("unix", e.socket)
+		if err != nil {
+			b.Skipf("unix sockets are not supported: %s", err)
+		}
+		socketServer := &http.Server{Handler: stubEngine()}
+		go func() {
+			_ = socketServer.Serve(l)
+		}()
+		defer socketServer.Close()
+
+		e.url = "http://localhost"
+		benchmarkDo(b, e)
+	})
+}
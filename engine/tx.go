@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TxOptions configures an interactive transaction
+type TxOptions struct {
+	// MaxWait is the maximum amount of time to wait for the query engine to start the transaction
+	MaxWait time.Duration
+	// Timeout is the maximum amount of time the transaction can run before it is rolled back
+	Timeout time.Duration
+}
+
+// Transactional is implemented by engines which support interactive transactions
+type Transactional interface {
+	StartTx(ctx context.Context, opts TxOptions) (string, error)
+	CommitTx(ctx context.Context, id string) error
+	RollbackTx(ctx context.Context, id string) error
+}
+
+type txIDKey struct{}
+
+// WithTxID returns a context which runs all queries within the interactive transaction with the given id
+func WithTxID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, txIDKey{}, id)
+}
+
+// TxID returns the interactive transaction id of a context, or an empty string if there is none
+func TxID(ctx context.Context) string {
+	id, _ := ctx.Value(txIDKey{}).(string)
+	return id
+}
+
+type txStartRequest struct {
+	MaxWait int64 `json:"max_wait"`
+	Timeout int64 `json:"timeout"`
+}
+
+type txStartResponse struct {
+	ID string `json:"id"`
+}
+
+// StartTx starts an interactive transaction and returns its id
+func (e *QueryEngine) StartTx(ctx context.Context, opts TxOptions) (string, error) {
+	payload := txStartRequest{
+		MaxWait: opts.MaxWait.Milliseconds(),
+		Timeout: opts.Timeout.Milliseconds(),
+	}
+
+	body, err := e.Request(ctx, "POST", "/transaction/start", payload)
+	if err != nil {
+		return "", fmt.Errorf("start transaction: %w", err)
+	}
+
+	var response txStartResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("json start transaction response unmarshal: %w", err)
+	}
+
+	if response.ID == "" {
+		return "", fmt.Errorf("start transaction: no transaction id in response %s", body)
+	}
+
+	return response.ID, nil
+}
+
+// CommitTx commits an interactive transaction
+func (e *QueryEngine) CommitTx(ctx context.Context, id string) error {
+	if _, err := e.Request(ctx, "POST", "/transaction/"+id+"/commit", map[string]interface{}{}); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RollbackTx rolls back an interactive transaction
+func (e *QueryEngine) RollbackTx(ctx context.Context, id string) error {
+	if _, err := e.Request(ctx, "POST", "/transaction/"+id+"/rollback", map[string]interface{}{}); err != nil {
+		return fmt.Errorf("rollback transaction: %w", err)
+	}
+	return nil
+}
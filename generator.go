@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
 	"path"
 
@@ -35,7 +36,7 @@ func reply(w io.Writer, data interface{}) error {
 func invokePrisma() error {
 	reader := bufio.NewReader(os.Stdin)
 
-	if logger.Enabled || writeDebugFile {
+	if logger.LevelEnabled(slog.LevelDebug) || writeDebugFile {
 		dir, _ := os.Getwd()
 		log.Printf("current working dir: %s", dir)
 	}
@@ -43,7 +44,7 @@ func invokePrisma() error {
 	for {
 		content, err := reader.ReadBytes('\n')
 		if errors.Is(err, io.EOF) {
-			logger.Debug.Printf("warning: ignoring EOF error. stdin: `%s`", content)
+			logger.Default().Debug("ignoring EOF error", "stdin", string(content))
 			return nil
 		}
 		if err != nil {
@@ -151,6 +151,45 @@ func (Document) Actions() []Action {
 	}}
 }
 
+// Aggregation describes an aggregation such as count, sum or avg
+type Aggregation struct {
+	Name        string
+	Action      string
+	Description string
+	// Fields is the kind of field reference the aggregation accepts, i.e. Prisma, Number or Comparable
+	Fields string
+}
+
+// Aggregations returns all aggregations supported by aggregate and group by queries.
+func (Document) Aggregations() []Aggregation {
+	return []Aggregation{{
+		Name:        "Count",
+		Action:      "_count",
+		Description: "number of non-null values",
+		Fields:      "Prisma",
+	}, {
+		Name:        "Sum",
+		Action:      "_sum",
+		Description: "sum",
+		Fields:      "Number",
+	}, {
+		Name:        "Avg",
+		Action:      "_avg",
+		Description: "average",
+		Fields:      "Number",
+	}, {
+		Name:        "Min",
+		Action:      "_min",
+		Description: "minimum",
+		Fields:      "Comparable",
+	}, {
+		Name:        "Max",
+		Action:      "_max",
+		Description: "maximum",
+		Fields:      "Comparable",
+	}}
+}
+
 // Method defines the method for the virtual types method
 type Method struct {
 	Name   string
@@ -289,6 +328,26 @@ func (f Field) RequiredOnCreate() bool {
 	return true
 }
 
+// IsNumber returns whether a field is a numeric scalar which can be used in sum or avg aggregations
+func (f Field) IsNumber() bool {
+	if f.IsList || !f.Kind.IncludeInStruct() {
+		return false
+	}
+	switch f.Type {
+	case "Int", "Float", "Decimal", "BigInt":
+		return true
+	}
+	return false
+}
+
+// IsComparable returns whether a field can be used in min or max aggregations
+func (f Field) IsComparable() bool {
+	if f.IsList || !f.Kind.IncludeInStruct() {
+		return false
+	}
+	return f.Type != "Json" && f.Type != "Bytes"
+}
+
 // RelationMethod describes a method for relations
 type RelationMethod struct {
 	Name   string
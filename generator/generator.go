@@ -67,18 +67,75 @@ type ConnectorType string
 
 // ConnectorType values
 const (
-	ConnectorTypeMySQL      ConnectorType = "mysql"
-	ConnectorTypeMongo      ConnectorType = "mongo"
-	ConnectorTypeSQLite     ConnectorType = "sqlite"
-	ConnectorTypePostgreSQL ConnectorType = "postgresql"
+	ConnectorTypeMySQL       ConnectorType = "mysql"
+	ConnectorTypeMongo       ConnectorType = "mongo"
+	ConnectorTypeMongoDB     ConnectorType = "mongodb"
+	ConnectorTypeSQLite      ConnectorType = "sqlite"
+	ConnectorTypePostgreSQL  ConnectorType = "postgresql"
+	ConnectorTypeSQLServer   ConnectorType = "sqlserver"
+	ConnectorTypeCockroachDB ConnectorType = "cockroachdb"
 )
 
+// IsMongo returns whether the connector is MongoDB, which is reported as either mongo or mongodb
+func (c ConnectorType) IsMongo() bool {
+	return c == ConnectorTypeMongo || c == ConnectorTypeMongoDB
+}
+
+// SupportsCreateMany returns whether the connector supports bulk inserts via createMany
+func (c ConnectorType) SupportsCreateMany() bool {
+	return c != ConnectorTypeSQLite
+}
+
+// SupportsSkipDuplicates returns whether the connector supports skipDuplicates in createMany
+func (c ConnectorType) SupportsSkipDuplicates() bool {
+	return c.SupportsCreateMany() && !c.IsMongo() && c != ConnectorTypeSQLServer
+}
+
+// PingQuery returns a raw query to check whether the database is reachable, or an empty string if raw
+// queries can't be used for this
+func (c ConnectorType) PingQuery() string {
+	if c.IsMongo() {
+		return ""
+	}
+	return "SELECT 1"
+}
+
+// IsolationLevels returns the transaction isolation levels supported by the connector
+func (c ConnectorType) IsolationLevels() []string {
+	switch {
+	case c.IsMongo():
+		return nil
+	case c == ConnectorTypeSQLite, c == ConnectorTypeCockroachDB:
+		return []string{"Serializable"}
+	case c == ConnectorTypeSQLServer:
+		return []string{"ReadUncommitted", "ReadCommitted", "RepeatableRead", "Snapshot", "Serializable"}
+	default:
+		return []string{"ReadUncommitted", "ReadCommitted", "RepeatableRead", "Serializable"}
+	}
+}
+
 // Datasource describes a Prisma data source of any database type.
 type Datasource struct {
-	Name          types.String  `json:"name"`
+	Name types.String `json:"name"`
+	// ConnectorType is only sent by older Prisma versions; use Connector() instead
 	ConnectorType ConnectorType `json:"connectorType"`
-	URL           EnvValue      `json:"url"`
-	Config        interface{}   `json:"config"`
+	// Provider describes the provider as defined in the schema
+	Provider ConnectorType `json:"provider"`
+	// ActiveProvider describes the connector which is actually used
+	ActiveProvider ConnectorType `json:"activeProvider"`
+	URL            EnvValue      `json:"url"`
+	Config         interface{}   `json:"config"`
+}
+
+// Connector returns the connector type of the datasource
+func (d Datasource) Connector() ConnectorType {
+	if d.ActiveProvider != "" {
+		return d.ActiveProvider
+	}
+	if d.Provider != "" {
+		return d.Provider
+	}
+	return d.ConnectorType
 }
 
 // EnvValue contains a string value and optionally information if, and if yes from where, an env var is used for this value.
@@ -33,7 +33,7 @@ func addDefaults(input *Root) {
 			targets = append(targets, BinaryTarget{Value: t})
 		}
 		input.Generator.BinaryTargets = targets
-		logger.Debug.Printf("overriding binary targets: %+v", targets)
+		logger.Default().Debug("overriding binary targets", "targets", targets)
 	}
 }
 
@@ -42,7 +42,7 @@ func Run(input *Root) error {
 	addDefaults(input)
 
 	if input.Generator.Config.DisableGitignore != "true" && input.Generator.Config.DisableGoBinaries != "true" {
-		logger.Debug.Printf("writing gitignore file")
+		logger.Default().Debug("writing gitignore file")
 		// generate a gitignore into the folder
 		var gitignore = "# gitignore generated by Prisma Client Go. DO NOT EDIT.\n*_gen.go\n"
 		if err := os.MkdirAll(input.Generator.Output.Value, os.ModePerm); err != nil {
@@ -149,14 +149,14 @@ func generateBinaries(input *Root) error {
 	}
 
 	if input.GetEngineType() == "dataproxy" {
-		logger.Debug.Printf("using data proxy; not fetching any engines")
+		logger.Default().Debug("using data proxy; not fetching any engines")
 		return nil
 	}
 
 	var targets []string
 	var isNonLinux bool
 
-	logger.Debug.Printf("defined binary targets: %v", input.Generator.BinaryTargets)
+	logger.Default().Debug("defined binary targets", "targets", input.Generator.BinaryTargets)
 
 	for _, target := range input.Generator.BinaryTargets {
 		targets = append(targets, target.Value)
@@ -171,13 +171,13 @@ func generateBinaries(input *Root) error {
 		targets = add(targets, "native")
 	}
 
-	logger.Debug.Printf("final binary targets: %v", targets)
+	logger.Default().Debug("final binary targets", "targets", targets)
 
 	// TODO refactor
 	for _, name := range targets {
 		if name == "native" {
 			name = platform.BinaryPlatformNameStatic()
-			logger.Debug.Printf("swapping 'native' binary target with '%s'", name)
+			logger.Default().Debug("swapping 'native' binary target", "target", name)
 		}
 
 		name = TransformBinaryTarget(name)
@@ -218,7 +218,7 @@ func generateQueryEngineFiles(binaryTargets []string, pkg, outputDir string) err
 			return fmt.Errorf("generate write go file: %w", err)
 		}
 
-		logger.Debug.Printf("write go file at %s", filename)
+		logger.Default().Debug("write go file", "path", filename)
 	}
 
 	return nil
@@ -237,7 +237,7 @@ func TransformBinaryTarget(name string) string {
 	// TODO this is a temp fix as the exact alpine libraries are not working
 	if name == "linux" || strings.Contains(name, "musl") {
 		name = "linux-static-" + platform.Arch()
-		logger.Debug.Printf("overriding binary name with '%s' due to linux or musl", name)
+		logger.Default().Debug("overriding binary name due to linux or musl", "name", name)
 	}
 	return name
 }
@@ -1,28 +1,109 @@
+// Package logger contains the logger of Prisma Client Go, which is based on log/slog.
+//
+// By default, messages of level info and above are printed as text to stdout. The level can be set via the
+// PRISMA_CLIENT_GO_LOG environment variable or Level, and the handler can be replaced with SetHandler.
+// Clients can use their own handler via the WithLogHandler client option.
 package logger
 
 import (
-	"io"
-	"log"
+	"context"
+	"log/slog"
 	"os"
+	"strings"
+	"sync"
 )
 
-const flag = log.Ldate | log.Lmicroseconds
+var v = os.Getenv("PRISMA_CLIENT_GO_LOG")
 
-// TODO add log levels
+// Level is the minimum level of the built-in handler, initially set via PRISMA_CLIENT_GO_LOG.
+// Supported values are debug, info, warn and error; defaults to info.
+var Level = new(slog.LevelVar)
 
-var v = os.Getenv("PRISMA_CLIENT_GO_LOG")
-var Enabled = v != ""
+var (
+	mu       sync.RWMutex
+	defaultL *slog.Logger
+)
+
+// Enabled returns whether Level enables debug logs
+//
+// deprecated: use LevelEnabled
+func Enabled() bool {
+	return Level.Level() <= slog.LevelDebug
+}
+
+// Debug logs messages at debug level via the default logger
+//
+// deprecated: use Default().Debug
+var Debug = slog.NewLogLogger(defaultHandler{}, slog.LevelDebug)
 
-var Debug *log.Logger
-var Info *log.Logger
+// Info logs messages at info level via the default logger
+//
+// deprecated: use Default().Info
+var Info = slog.NewLogLogger(defaultHandler{}, slog.LevelInfo)
 
 func init() {
-	discard := log.New(io.Discard, "", 0)
+	Level.Set(ParseLevel(v))
 
-	Debug = discard
-	if Enabled {
-		Debug = log.New(os.Stdout, "[prisma-client-go] DEBUG: ", flag)
-	}
+	SetHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: Level,
+	}))
+}
+
+// Default returns the default logger, which is used when no handler is set for a client
+func Default() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultL
+}
+
+// SetHandler replaces the handler of the default logger. Level only applies to the built-in handler,
+// so h should do its own level filtering. Should be called before creating any clients.
+func SetHandler(h slog.Handler) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultL = slog.New(h)
+}
 
-	Info = log.New(os.Stdout, "[prisma-client-go] INFO: ", flag)
+// defaultHandler passes records to the handler of the current default logger, so loggers created from it
+// follow SetHandler
+type defaultHandler struct{}
+
+func (defaultHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return Default().Handler().Enabled(ctx, l)
+}
+
+func (defaultHandler) Handle(ctx context.Context, r slog.Record) error {
+	return Default().Handler().Handle(ctx, r)
+}
+
+func (defaultHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return Default().Handler().WithAttrs(attrs)
+}
+
+func (defaultHandler) WithGroup(name string) slog.Handler {
+	return Default().Handler().WithGroup(name)
+}
+
+// LevelEnabled returns whether messages of the given level are logged by the default logger
+func LevelEnabled(l slog.Level) bool {
+	return Default().Enabled(context.Background(), l)
+}
+
+// ParseLevel parses a log level such as debug, info, warn or error. An empty value returns info.
+// Any other value enables debug logs, as PRISMA_CLIENT_GO_LOG used to be a switch for debug logs.
+func ParseLevel(s string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return slog.LevelInfo
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelDebug
+	}
 }
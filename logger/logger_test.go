@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		value string
+		want  slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{"true", slog.LevelDebug},
+	}
+	for _, tt := range tests {
+		if got := ParseLevel(tt.value); got != tt.want {
+			t.Errorf("ParseLevel(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSetHandler(t *testing.T) {
+	defer SetHandler(Default().Handler())
+
+	var buf bytes.Buffer
+	SetHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelWarn,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	Default().Info("hidden")
+	Default().Warn("shown", "model", "User")
+	Info.Printf("legacy %s", "hidden")
+
+	if got, want := buf.String(), "level=WARN msg=shown model=User\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if LevelEnabled(slog.LevelInfo) || !LevelEnabled(slog.LevelError) {
+		t.Errorf("expected LevelEnabled to use the handler's level")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	defer Level.Set(Level.Level())
+
+	Level.Set(slog.LevelInfo)
+	if Enabled() {
+		t.Errorf("expected debug logs to be disabled at info level")
+	}
+	Level.Set(slog.LevelDebug)
+	if !Enabled() {
+		t.Errorf("expected debug logs to be enabled after setting the level to debug")
+	}
+}
+
+func TestSetHandler_legacy(t *testing.T) {
+	defer SetHandler(Default().Handler())
+
+	var buf bytes.Buffer
+	SetHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	Debug.Printf("hidden")
+	Info.Printf("legacy %s", "shown")
+
+	if got, want := buf.String(), "level=INFO msg=\"legacy shown\"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/vnsoft2014/prisma-client-go/cli"
@@ -11,9 +12,20 @@ import (
 )
 
 func main() {
-	if len(os.Args) > 1 {
-		args := os.Args[1:]
-		logger.Debug.Printf("invoking command %+v", args)
+	args, level, ok, err := cli.ParseLogLevel(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if ok {
+		logger.Level.Set(level)
+		// the generator is invoked by the prisma CLI in a separate process, which reads the level from the env
+		if err := os.Setenv("PRISMA_CLIENT_GO_LOG", strings.ToLower(level.String())); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(args) > 0 {
+		logger.Default().Debug("invoking command", "args", args)
 
 		if args[0] == "prefetch" {
 			// just run prisma -v to trigger the download
@@ -34,14 +46,14 @@ func main() {
 
 	// running the prisma generator
 
-	logger.Debug.Printf("invoking prisma")
+	logger.Default().Debug("invoking prisma")
 
 	// if this wasn't actually invoked by the prisma generator, print a warning and exit
 	if os.Getenv("PRISMA_GENERATOR_INVOCATION") == "" {
-		logger.Info.Printf("This command is only meant to be invoked internally. Please run the following instead:")
-		logger.Info.Printf("`go run github.com/vnsoft2014/prisma-client-go <command>`")
-		logger.Info.Printf("e.g.")
-		logger.Info.Printf("`go run github.com/vnsoft2014/prisma-client-go generate`")
+		logger.Default().Info(
+			"This command is only meant to be invoked internally. Please run `go run github.com/vnsoft2014/prisma-client-go <command>` instead.",
+			"example", "go run github.com/vnsoft2014/prisma-client-go generate",
+		)
 		os.Exit(1)
 	}
 
@@ -59,5 +71,5 @@ func main() {
 		os.Exit(1)
 	}
 
-	logger.Debug.Printf("success")
+	logger.Default().Debug("success")
 }
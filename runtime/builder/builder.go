@@ -4,11 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/vnsoft2014/prisma-client-go/engine"
-	"github.com/vnsoft2014/prisma-client-go/logger"
 )
 
 type Input struct {
@@ -76,7 +76,14 @@ type Query struct {
 	// Start time of the request for tracing
 	Start time.Time
 
+	// UsePrimary forces a read query to be sent to the primary engine instead of a read replica
+	UsePrimary bool
+
 	TxResult chan []byte
+
+	// Err is set if the query is invalid, e.g. when Skip is used without OrderBy in a group by query.
+	// Exec and transactions return it instead of sending the query.
+	Err error
 }
 
 func (q Query) Build() string {
@@ -178,6 +185,12 @@ func (q Query) buildFields(list bool, wrapList bool, fields []Field) string {
 	// this is necessary for json filters and more
 	uniques := make(map[string]*Field)
 	for i, f := range fields {
+		// unnamed objects are list items, e.g. rows in a createMany query, which must not be joined
+		if f.Name == "" && f.Fields != nil {
+			final = append(final, f)
+			continue
+		}
+
 		if _, ok := uniques[f.Name]; ok {
 			// check if field is a model operation
 			if f.Fields != nil && f.Name != "AND" && f.Name != "OR" && f.Name != "NOT" {
@@ -200,7 +213,10 @@ func (q Query) buildFields(list bool, wrapList bool, fields []Field) string {
 	}
 
 	for _, f := range final {
-		if wrapList {
+		// unnamed objects are already wrapped by their sub-fields
+		wrap := wrapList && (f.Name != "" || f.Fields == nil)
+
+		if wrap {
 			builder.WriteString("{")
 		}
 
@@ -228,7 +244,7 @@ func (q Query) buildFields(list bool, wrapList bool, fields []Field) string {
 			builder.WriteString("]")
 		}
 
-		if wrapList {
+		if wrap {
 			builder.WriteString("}")
 		}
 
@@ -243,11 +259,20 @@ func (q Query) buildFields(list bool, wrapList bool, fields []Field) string {
 }
 
 func (q Query) Exec(ctx context.Context, into interface{}) error {
-	payload := engine.GQLRequest{
-		Query:     q.Build(),
-		Variables: map[string]interface{}{},
+	if q.Err != nil {
+		return q.Err
 	}
-	return q.Do(ctx, payload, into)
+	req := &Request{
+		Query:  &q,
+		Result: into,
+	}
+	return Intercept(ctx, q.Engine, req, func(ctx context.Context, req *Request) error {
+		payload := engine.GQLRequest{
+			Query:     req.Query.Build(),
+			Variables: map[string]interface{}{},
+		}
+		return req.Query.Do(ctx, payload, req.Result)
+	})
 }
 
 func (q Query) Do(ctx context.Context, payload interface{}, into interface{}) error {
@@ -255,13 +280,40 @@ func (q Query) Do(ctx context.Context, payload interface{}, into interface{}) er
 		return fmt.Errorf("client.Prisma.Connect() needs to be called before sending queries")
 	}
 
-	logger.Debug.Printf("[timing] building %q", time.Since(q.Start))
+	log := engine.Logger(q.Engine)
+	if !log.Enabled(ctx, slog.LevelDebug) {
+		return q.target().Do(ctx, payload, into)
+	}
+
+	log = log.With("model", q.Model, "method", q.Method)
+	log.DebugContext(ctx, "query built", "duration", time.Since(q.Start))
 
-	err := q.Engine.Do(ctx, payload, into)
-	now := time.Now()
-	totalDuration := now.Sub(q.Start)
-	logger.Debug.Printf("[timing] TOTAL %q", totalDuration)
-	return err
+	err := q.target().Do(ctx, payload, into)
+	if err != nil {
+		log.DebugContext(ctx, "query failed", "duration", time.Since(q.Start), "error", err)
+		return err
+	}
+	log.DebugContext(ctx, "query done", "duration", time.Since(q.Start))
+	return nil
+}
+
+// IsRead returns whether the query only reads data, i.e. it is a query operation or a raw query
+func (q Query) IsRead() bool {
+	return q.Operation == "query" || q.Method == "queryRaw"
+}
+
+// target returns the engine the query is sent to. Read queries are routed to a replica
+// if the engine has any, unless UsePrimary is set.
+func (q Query) target() engine.Engine {
+	if q.UsePrimary || !q.IsRead() {
+		return q.Engine
+	}
+	if r, ok := q.Engine.(engine.ReadRouter); ok {
+		if replica := r.ReadReplica(); replica != nil {
+			return replica
+		}
+	}
+	return q.Engine
 }
 
 func Value(value interface{}) []byte {
@@ -0,0 +1,129 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestQuery_Build(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{{
+		name: "find unique",
+		query: Query{
+			Operation: "query",
+			Method:    "findUnique",
+			Model:     "User",
+			Inputs: []Input{{
+				Name: "where",
+				Fields: []Field{{
+					Name:  "id",
+					Value: "a",
+				}},
+			}},
+			Outputs: []Output{{Name: "id"}, {Name: "email"}},
+		},
+		want: `query {result: findUniqueUser(where:{id:"a",},) {id email }}`,
+	}, {
+		name: "list of objects",
+		query: Query{
+			Operation: "mutation",
+			Method:    "createMany",
+			Model:     "User",
+			Inputs: []Input{{
+				Name:     "data",
+				WrapList: true,
+				Fields: []Field{{
+					Fields: []Field{{Name: "email", Value: "a"}, {Name: "age", Value: 1}},
+				}, {
+					Fields: []Field{{Name: "email", Value: "b"}},
+				}},
+			}},
+			Outputs: []Output{{Name: "count"}},
+		},
+		want: `mutation {result: createManyUser(data:[{email:"a",age:1,},{email:"b",},],) {count }}`,
+	}, {
+		name: "wrapped list of named fields",
+		query: Query{
+			Operation: "query",
+			Method:    "findMany",
+			Model:     "User",
+			Inputs: []Input{{
+				Name:     "orderBy",
+				WrapList: true,
+				Fields:   []Field{{Name: "email", Value: "asc"}, {Name: "age", Value: "desc"}},
+			}},
+			Outputs: []Output{{Name: "id"}},
+		},
+		want: `query {result: findManyUser(orderBy:[{email:"asc"},{age:"desc"},],) {id }}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Build(); got != tt.want {
+				t.Errorf("Build() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+type loggingEngine struct {
+	recordingEngine
+	log *slog.Logger
+}
+
+func (e loggingEngine) Logger() *slog.Logger {
+	return e.log
+}
+
+func TestQuery_Do_log(t *testing.T) {
+	var buf bytes.Buffer
+	var calls []string
+	e := loggingEngine{
+		recordingEngine: recordingEngine{name: "primary", calls: &calls},
+		log:             slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	}
+
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findMany"
+	q.Model = "User"
+
+	var into map[string]interface{}
+	if err := q.Do(context.Background(), nil, &into); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{`msg="query done"`, "model=User", "method=findMany", "duration="} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected log to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestQuery_Exec_err(t *testing.T) {
+	var calls []string
+	invalid := errors.New("invalid query")
+
+	q := NewQuery()
+	q.Engine = recordingEngine{name: "primary", calls: &calls}
+	q.Operation = "query"
+	q.Method = "groupBy"
+	q.Model = "User"
+	q.Err = invalid
+
+	var into map[string]interface{}
+	if err := q.Exec(context.Background(), &into); err != invalid {
+		t.Fatalf("Exec() error = %v, want %v", err, invalid)
+	}
+	if len(calls) > 0 {
+		t.Errorf("Exec() sent %v, want no request", calls)
+	}
+}
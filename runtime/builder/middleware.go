@@ -0,0 +1,71 @@
+package builder
+
+import (
+	"context"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+// Request describes a query, raw query or transaction batch which is about to be sent to the query engine.
+// Middleware can inspect and modify the request before calling the next handler.
+type Request struct {
+	// Query contains the query for single queries and raw queries; nil for transaction batches
+	Query *Query
+
+	// Batch contains the queries of a transaction batch; nil for single queries.
+	// Middleware can add, remove or reorder queries; each result is delivered to the query sent at its position.
+	Batch []*Query
+
+	// Result is a pointer to the value which the response is unmarshaled into.
+	// Middleware which doesn't call the next handler can set the result itself.
+	Result interface{}
+}
+
+// IsBatch returns whether the request is a transaction batch
+func (r *Request) IsBatch() bool {
+	return r.Query == nil
+}
+
+// Handler sends a request to the query engine
+type Handler func(ctx context.Context, req *Request) error
+
+// Middleware wraps a handler, e.g. to log, time or modify requests, or to wrap errors.
+// It can short-circuit a request by not calling next.
+type Middleware func(next Handler) Handler
+
+// Instrumentation provides a middleware which records requests, such as the OpenTelemetry tracing and metrics of
+// the telemetry package. The generated client accepts it so it doesn't depend on the instrumentation libraries.
+type Instrumentation interface {
+	Middleware() Middleware
+}
+
+// Intercepter is implemented by engines which wrap requests in middleware, such as the generated client
+type Intercepter interface {
+	Middleware() []Middleware
+}
+
+// Intercept sends the request via handler, wrapped in all middleware of the engine.
+// The first registered middleware is the outermost one.
+// The request as passed to handler is available to the engine via RequestFromContext.
+func Intercept(ctx context.Context, e engine.Engine, req *Request, handler Handler) error {
+	send := handler
+	handler = func(ctx context.Context, req *Request) error {
+		return send(context.WithValue(ctx, requestKey{}, req), req)
+	}
+	if i, ok := e.(Intercepter); ok {
+		middleware := i.Middleware()
+		for j := len(middleware) - 1; j >= 0; j-- {
+			handler = middleware[j](handler)
+		}
+	}
+	return handler(ctx, req)
+}
+
+type requestKey struct{}
+
+// RequestFromContext returns the request which is being sent, so engines can inspect the structured queries
+// instead of the built query strings. It returns nil if the query was not sent via Intercept.
+func RequestFromContext(ctx context.Context) *Request {
+	req, _ := ctx.Value(requestKey{}).(*Request)
+	return req
+}
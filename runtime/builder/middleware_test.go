@@ -0,0 +1,151 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+type interceptedEngine struct {
+	engine.Engine
+	middleware []Middleware
+	queries    []string
+}
+
+func (e *interceptedEngine) Middleware() []Middleware {
+	return e.middleware
+}
+
+func (e *interceptedEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	e.queries = append(e.queries, payload.(engine.GQLRequest).Query)
+	return json.Unmarshal([]byte(`{"id":"a"}`), v)
+}
+
+func TestQuery_Exec_middleware(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) error {
+				calls = append(calls, name+" "+req.Query.Method+req.Query.Model)
+				return next(ctx, req)
+			}
+		}
+	}
+
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name        string
+		middleware  []Middleware
+		wantCalls   []string
+		wantQueries []string
+		wantResult  map[string]interface{}
+		wantErr     error
+	}{{
+		name:        "order",
+		middleware:  []Middleware{record("a"), record("b")},
+		wantCalls:   []string{"a findUniqueUser", "b findUniqueUser"},
+		wantQueries: []string{`query {result: findUniqueUser(where:{id:"a",},) {id }}`},
+		wantResult:  map[string]interface{}{"id": "a"},
+	}, {
+		name: "modify inputs",
+		middleware: []Middleware{func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) error {
+				req.Query.Inputs[0].Fields = append(req.Query.Inputs[0].Fields, Field{
+					Name:  "deleted",
+					Value: false,
+				})
+				return next(ctx, req)
+			}
+		}},
+		wantQueries: []string{`query {result: findUniqueUser(where:{id:"a",deleted:false,},) {id }}`},
+		wantResult:  map[string]interface{}{"id": "a"},
+	}, {
+		name: "short-circuit",
+		middleware: []Middleware{func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) error {
+				return json.Unmarshal([]byte(`{"id":"cached"}`), req.Result)
+			}
+		}},
+		wantResult: map[string]interface{}{"id": "cached"},
+	}, {
+		name: "wrap error",
+		middleware: []Middleware{func(next Handler) Handler {
+			return func(ctx context.Context, req *Request) error {
+				return fmt.Errorf("audit: %w", errFailed)
+			}
+		}},
+		wantErr: errFailed,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			e := &interceptedEngine{middleware: tt.middleware}
+			q := NewQuery()
+			q.Engine = e
+			q.Operation = "query"
+			q.Method = "findUnique"
+			q.Model = "User"
+			q.Inputs = []Input{{
+				Name:   "where",
+				Fields: []Field{{Name: "id", Value: "a"}},
+			}}
+			q.Outputs = []Output{{Name: "id"}}
+
+			var result map[string]interface{}
+			err := q.Exec(context.Background(), &result)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Exec() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("Exec() calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(e.queries, tt.wantQueries) {
+				t.Errorf("Exec() queries = %v, want %v", e.queries, tt.wantQueries)
+			}
+			if !reflect.DeepEqual(result, tt.wantResult) {
+				t.Errorf("Exec() result = %v, want %v", result, tt.wantResult)
+			}
+		})
+	}
+}
+
+type contextEngine struct {
+	engine.Engine
+	req *Request
+}
+
+func (e *contextEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	e.req = RequestFromContext(ctx)
+	return json.Unmarshal([]byte(`{}`), v)
+}
+
+func TestRequestFromContext(t *testing.T) {
+	e := &contextEngine{}
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findMany"
+	q.Model = "User"
+
+	var result map[string]interface{}
+	if err := q.Exec(context.Background(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if e.req == nil || e.req.Query == nil || e.req.Query.Method != "findMany" {
+		t.Fatalf("expected the request to be available to the engine, got %+v", e.req)
+	}
+
+	e.req = nil
+	if err := q.Do(context.Background(), engine.GQLRequest{}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if e.req != nil {
+		t.Errorf("expected no request without Intercept, got %+v", e.req)
+	}
+}
@@ -0,0 +1,117 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+type recordingEngine struct {
+	engine.Engine
+	name  string
+	calls *[]string
+}
+
+func (e recordingEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	*e.calls = append(*e.calls, e.name)
+	return json.Unmarshal([]byte(`{}`), v)
+}
+
+type routedEngine struct {
+	recordingEngine
+	replica engine.Engine
+}
+
+func (e routedEngine) ReadReplica() engine.Engine {
+	return e.replica
+}
+
+func TestQuery_Exec_readReplica(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		method    string
+		primary   bool
+		want      string
+	}{{
+		name:      "find many",
+		operation: "query",
+		method:    "findMany",
+		want:      "replica",
+	}, {
+		name:      "aggregate",
+		operation: "query",
+		method:    "aggregate",
+		want:      "replica",
+	}, {
+		name:      "query raw",
+		operation: "mutation",
+		method:    "queryRaw",
+		want:      "replica",
+	}, {
+		name:      "create",
+		operation: "mutation",
+		method:    "createOne",
+		want:      "primary",
+	}, {
+		name:      "execute raw",
+		operation: "mutation",
+		method:    "executeRaw",
+		want:      "primary",
+	}, {
+		name:      "use primary",
+		operation: "query",
+		method:    "findUnique",
+		primary:   true,
+		want:      "primary",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			e := routedEngine{
+				recordingEngine: recordingEngine{name: "primary", calls: &calls},
+				replica:         recordingEngine{name: "replica", calls: &calls},
+			}
+
+			q := NewQuery()
+			q.Engine = e
+			q.Operation = tt.operation
+			q.Method = tt.method
+			q.Model = "User"
+			q.UsePrimary = tt.primary
+
+			var v map[string]interface{}
+			if err := q.Exec(context.Background(), &v); err != nil {
+				t.Fatal(err)
+			}
+
+			if want := []string{tt.want}; !reflect.DeepEqual(calls, want) {
+				t.Errorf("calls = %v, want %v", calls, want)
+			}
+		})
+	}
+}
+
+func TestQuery_Exec_noReplica(t *testing.T) {
+	var calls []string
+	e := routedEngine{
+		recordingEngine: recordingEngine{name: "primary", calls: &calls},
+	}
+
+	q := NewQuery()
+	q.Engine = e
+	q.Operation = "query"
+	q.Method = "findMany"
+
+	var v map[string]interface{}
+	if err := q.Exec(context.Background(), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"primary"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
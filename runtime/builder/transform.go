@@ -13,3 +13,82 @@ func TransformEquals(fields []Field) []Field {
 	}
 	return fields
 }
+
+// AppendOutputs adds fields as a sub-selection to the output with the given name, creating it if needed.
+// This is used for aggregations such as `_count { ... }` which can be requested multiple times.
+func AppendOutputs(outputs []Output, name string, fields []Output) []Output {
+	result := make([]Output, len(outputs))
+	copy(result, outputs)
+	for i, o := range result {
+		if o.Name == name {
+			result[i].Outputs = append(append([]Output{}, o.Outputs...), fields...)
+			return result
+		}
+	}
+	return append(result, Output{
+		Name:    name,
+		Outputs: fields,
+	})
+}
+
+// SelectOutputs limits scalar outputs to the given fields, while nested outputs such as relations are kept.
+// If no outputs would be left, which the query engine rejects, the outputs are returned unchanged.
+func SelectOutputs(outputs []Output, fields []string) []Output {
+	var result []Output
+	for _, f := range fields {
+		result = append(result, Output{Name: f})
+	}
+	for _, o := range outputs {
+		if o.Outputs != nil {
+			result = append(result, o)
+		}
+	}
+	if len(result) == 0 {
+		return outputs
+	}
+	return result
+}
+
+// OmitOutputs removes the given fields from the outputs.
+// If no outputs would be left, which the query engine rejects, the outputs are returned unchanged.
+func OmitOutputs(outputs []Output, fields []string) []Output {
+	var result []Output
+	for _, o := range outputs {
+		if !contains(fields, o.Name) {
+			result = append(result, o)
+		}
+	}
+	if len(result) == 0 {
+		return outputs
+	}
+	return result
+}
+
+// IsSelected returns whether a field is part of the outputs.
+func IsSelected(outputs []Output, field string) bool {
+	for _, o := range outputs {
+		if o.Name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// HasInput returns whether any of the given inputs is set.
+func HasInput(inputs []Input, names ...string) bool {
+	for _, i := range inputs {
+		if contains(names, i.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
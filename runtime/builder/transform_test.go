@@ -0,0 +1,127 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+var defaultOutputs = []Output{{Name: "id"}, {Name: "email"}, {Name: "name"}}
+
+var postsOutput = Output{Name: "posts", Outputs: []Output{{Name: "id"}}}
+
+func TestSelectOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []Output
+		fields  []string
+		want    []Output
+	}{{
+		name:    "fields",
+		outputs: defaultOutputs,
+		fields:  []string{"id", "name"},
+		want:    []Output{{Name: "id"}, {Name: "name"}},
+	}, {
+		name:    "keeps relations",
+		outputs: append(append([]Output{}, defaultOutputs...), postsOutput),
+		fields:  []string{"email"},
+		want:    []Output{{Name: "email"}, postsOutput},
+	}, {
+		name:    "only relations",
+		outputs: append(append([]Output{}, defaultOutputs...), postsOutput),
+		want:    []Output{postsOutput},
+	}, {
+		name:    "no fields keeps the default outputs",
+		outputs: defaultOutputs,
+		want:    defaultOutputs,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SelectOutputs(tt.outputs, tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectOutputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOmitOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []Output
+		fields  []string
+		want    []Output
+	}{{
+		name:    "fields",
+		outputs: defaultOutputs,
+		fields:  []string{"email"},
+		want:    []Output{{Name: "id"}, {Name: "name"}},
+	}, {
+		name:    "unknown field",
+		outputs: defaultOutputs,
+		fields:  []string{"password"},
+		want:    defaultOutputs,
+	}, {
+		name:    "no fields",
+		outputs: defaultOutputs,
+		want:    defaultOutputs,
+	}, {
+		name:    "all fields keeps the default outputs",
+		outputs: defaultOutputs,
+		fields:  []string{"id", "email", "name"},
+		want:    defaultOutputs,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OmitOutputs(tt.outputs, tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OmitOutputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSelected(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []Output
+		field   string
+		want    bool
+	}{{
+		name:    "selected",
+		outputs: defaultOutputs,
+		field:   "email",
+		want:    true,
+	}, {
+		name:    "not selected",
+		outputs: SelectOutputs(defaultOutputs, []string{"id"}),
+		field:   "email",
+	}, {
+		name:    "omitted",
+		outputs: OmitOutputs(defaultOutputs, []string{"email"}),
+		field:   "email",
+	}, {
+		name:    "relation",
+		outputs: []Output{postsOutput},
+		field:   "posts",
+		want:    true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSelected(tt.outputs, tt.field); got != tt.want {
+				t.Errorf("IsSelected() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasInput(t *testing.T) {
+	inputs := []Input{{Name: "where"}, {Name: "take", Value: 5}}
+
+	if !HasInput(inputs, "skip", "take") {
+		t.Errorf("expected take to be found")
+	}
+	if HasInput(inputs, "orderBy") {
+		t.Errorf("expected orderBy not to be found")
+	}
+	if HasInput(nil, "take") {
+		t.Errorf("expected no input to be found in an empty list")
+	}
+}
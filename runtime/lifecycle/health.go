@@ -0,0 +1,90 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/raw"
+)
+
+// HealthOption configures a health check
+type HealthOption func(*healthOptions)
+
+type healthOptions struct {
+	database bool
+}
+
+// WithDatabase additionally checks whether the database is reachable by sending a query such as SELECT 1
+func WithDatabase() HealthOption {
+	return func(o *healthOptions) {
+		o.database = true
+	}
+}
+
+// closed is returned by Ready for engines which don't report their readiness
+var closed = make(chan struct{})
+
+func init() {
+	close(closed)
+}
+
+// Health pings the Prisma query engine and returns its name, version, uptime and last error.
+// It returns an error if the engine, or with WithDatabase the database, is not reachable.
+//
+// Example:
+//
+//	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+//	  if _, err := client.Prisma.Health(r.Context(), lifecycle.WithDatabase()); err != nil {
+//	    http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//	  }
+//	})
+func (c *Lifecycle) Health(ctx context.Context, opts ...HealthOption) (*engine.Health, error) {
+	var options healthOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	h := &engine.Health{
+		Name: c.Engine.Name(),
+	}
+	if checker, ok := c.Engine.(engine.HealthChecker); ok {
+		var err error
+		h, err = checker.Health(ctx)
+		if err != nil {
+			return h, err
+		}
+	}
+
+	if options.database {
+		if c.PingQuery == "" {
+			return h, fmt.Errorf("database health checks are not supported for this database")
+		}
+
+		var result interface{}
+		if err := (raw.Raw{Engine: c.Engine}).QueryRaw(c.PingQuery).UsePrimary().Exec(ctx, &result); err != nil {
+			return h, fmt.Errorf("database health check: %w", err)
+		}
+	}
+
+	return h, nil
+}
+
+// Ready returns a channel which is closed once the client is connected and ready to send queries.
+// After a crash of the query engine or Disconnect, a new channel is returned, which is closed once the query
+// engine is ready again.
+//
+// Example:
+//
+//	select {
+//	case <-client.Prisma.Ready():
+//	  // ready
+//	case <-time.After(30 * time.Second):
+//	  panic("client is not ready")
+//	}
+func (c *Lifecycle) Ready() <-chan struct{} {
+	if checker, ok := c.Engine.(engine.HealthChecker); ok {
+		return checker.Ready()
+	}
+	return closed
+}
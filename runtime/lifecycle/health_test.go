@@ -0,0 +1,104 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+type pingEngine struct {
+	engine.Engine
+	queries []string
+	err     error
+}
+
+func (e *pingEngine) Name() string {
+	return "ping"
+}
+
+func (e *pingEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	e.queries = append(e.queries, payload.(engine.GQLRequest).Query)
+	if e.err != nil {
+		return e.err
+	}
+	return json.Unmarshal([]byte(`[{"1":1}]`), v)
+}
+
+func TestLifecycle_Health(t *testing.T) {
+	errUnreachable := errors.New("unreachable")
+
+	tests := []struct {
+		name      string
+		pingQuery string
+		opts      []HealthOption
+		err       error
+		wantQuery string
+		wantErr   string
+	}{{
+		name:      "engine only",
+		pingQuery: "SELECT 1",
+	}, {
+		name:      "database",
+		pingQuery: "SELECT 1",
+		opts:      []HealthOption{WithDatabase()},
+		wantQuery: `queryRaw(query:"SELECT 1",parameters:"[]",)`,
+	}, {
+		name:      "database unreachable",
+		pingQuery: "SELECT 1",
+		opts:      []HealthOption{WithDatabase()},
+		err:       errUnreachable,
+		wantQuery: `queryRaw(query:"SELECT 1",parameters:"[]",)`,
+		wantErr:   "database health check",
+	}, {
+		name:    "database not supported",
+		opts:    []HealthOption{WithDatabase()},
+		wantErr: "not supported",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &pingEngine{err: tt.err}
+			c := &Lifecycle{
+				Engine:    e,
+				PingQuery: tt.pingQuery,
+			}
+
+			h, err := c.Health(context.Background(), tt.opts...)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("expected error to wrap %v, got %v", tt.err, err)
+			}
+			if h.Name != "ping" {
+				t.Errorf("Name = %s, want ping", h.Name)
+			}
+
+			if tt.wantQuery == "" {
+				if len(e.queries) != 0 {
+					t.Errorf("expected no queries, got %v", e.queries)
+				}
+				return
+			}
+			if len(e.queries) != 1 || !strings.Contains(e.queries[0], tt.wantQuery) {
+				t.Errorf("expected query containing %s, got %v", tt.wantQuery, e.queries)
+			}
+		})
+	}
+}
+
+func TestLifecycle_Ready(t *testing.T) {
+	c := &Lifecycle{Engine: &pingEngine{}}
+
+	select {
+	case <-c.Ready():
+	default:
+		t.Fatalf("engines which don't report their readiness must always be ready")
+	}
+}
@@ -1,11 +1,16 @@
 package lifecycle
 
 import (
+	"context"
+
 	"github.com/vnsoft2014/prisma-client-go/engine"
 )
 
 type Lifecycle struct {
 	Engine engine.Engine
+
+	// PingQuery is the raw query used to check whether the database is reachable; empty if not supported
+	PingQuery string
 }
 
 // Connect connects to the Prisma query engine. Required to call before accessing data.
@@ -26,10 +31,27 @@ func (c *Lifecycle) Connect() error {
 	return c.Engine.Connect()
 }
 
+// ConnectContext connects to the Prisma query engine like Connect, but stops waiting for the query engine
+// when ctx is done. The returned error wraps ctx.Err(), e.g. context.DeadlineExceeded.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := client.Prisma.ConnectContext(ctx); err != nil {
+//	  handle(err)
+//	}
+func (c *Lifecycle) ConnectContext(ctx context.Context) error {
+	return engine.Connect(ctx, c.Engine)
+}
+
 // Disconnect disconnects from the Prisma query engine.
 // This is usually invoked on kill signals in long running applications (like webservers),
 // or when no database access is needed anymore (like after executing a CLI command).
 //
+// Queries which are in flight are waited for up to engine.DefaultDisconnectTimeout; use DisconnectContext
+// to control how long to wait.
+//
 // Should be usually invoked directly after calling client.Prisma.Connect(), for example as follows:
 //
 //	// after client.Prisma.Connect()
@@ -42,3 +64,36 @@ func (c *Lifecycle) Connect() error {
 func (c *Lifecycle) Disconnect() error {
 	return c.Engine.Disconnect()
 }
+
+// DisconnectContext gracefully disconnects from the Prisma query engine.
+// New queries fail immediately, while queries which are in flight are waited for until ctx is done.
+// The query engine is stopped afterwards, and killed if it doesn't exit in time.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := client.Prisma.DisconnectContext(ctx); err != nil {
+//	  log.Printf("could not disconnect gracefully: %s", err)
+//	}
+func (c *Lifecycle) DisconnectContext(ctx context.Context) error {
+	if g, ok := c.Engine.(engine.GracefulDisconnecter); ok {
+		return g.DisconnectContext(ctx)
+	}
+	return c.Engine.Disconnect()
+}
+
+// OnQuery registers a function which is called for every database query executed by the Prisma query engine,
+// with the query, its parameters and its duration. It must be registered before calling Connect.
+// fn is called from a separate goroutine and should not block.
+//
+// Example:
+//
+//	client.Prisma.OnQuery(func(e engine.QueryEvent) {
+//	  log.Printf("%s %s took %s", e.Query, e.Params, e.Duration)
+//	})
+func (c *Lifecycle) OnQuery(fn func(engine.QueryEvent)) {
+	if l, ok := c.Engine.(engine.QueryLogger); ok {
+		l.OnQuery(fn)
+	}
+}
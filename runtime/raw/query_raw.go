@@ -23,6 +23,12 @@ func (r QueryExec) ExtractQuery() builder.Query {
 	return r.query
 }
 
+// UsePrimary sends the raw query to the primary database even if read replicas are configured
+func (r QueryExec) UsePrimary() QueryExec {
+	r.query.UsePrimary = true
+	return r
+}
+
 func (r QueryExec) Tx() TxQueryResult {
 	v := NewTxQueryResult()
 	v.query = r.query
@@ -9,7 +9,6 @@ import (
 	"github.com/shopspring/decimal"
 
 	"github.com/vnsoft2014/prisma-client-go/engine"
-	"github.com/vnsoft2014/prisma-client-go/logger"
 	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
 	"github.com/vnsoft2014/prisma-client-go/runtime/types/raw"
 )
@@ -18,9 +17,9 @@ type Raw struct {
 	Engine engine.Engine
 }
 
-func doRaw(engine engine.Engine, action string, query string, params ...interface{}) builder.Query {
+func doRaw(e engine.Engine, action string, query string, params ...interface{}) builder.Query {
 	q := builder.NewQuery()
-	q.Engine = engine
+	q.Engine = e
 	q.Operation = "mutation"
 	q.Method = action
 
@@ -56,7 +55,7 @@ func doRaw(engine engine.Engine, action string, query string, params ...interfac
 	}
 	newParams += "]"
 
-	logger.Debug.Printf("raw params: %s", newParams)
+	engine.Logger(e).Debug("raw params", "method", action, "params", newParams)
 
 	q.Inputs = append(q.Inputs, builder.Input{
 		Name:  "parameters",
@@ -0,0 +1,213 @@
+// Package telemetry provides OpenTelemetry tracing and metrics for Prisma Client Go.
+//
+// Enable it when creating a client:
+//
+//	t, err := telemetry.New()
+//	if err != nil {
+//		handle(err)
+//	}
+//	client := db.NewClient(db.WithTelemetry(t))
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
+)
+
+// instrumentationName is the name of the tracer and meter
+const instrumentationName = "github.com/vnsoft2014/prisma-client-go"
+
+// Span names
+const (
+	SpanOperation  = "prisma:client:operation"
+	SpanEngineHTTP = "prisma:engine:http"
+	SpanEngine     = "prisma:engine"
+)
+
+// Metric names
+const (
+	MetricDuration       = "prisma.client.operation.duration"
+	MetricEngineDuration = "prisma.client.engine.duration"
+	MetricErrors         = "prisma.client.operation.errors"
+)
+
+// Attribute keys
+const (
+	AttrOperation = attribute.Key("prisma.operation")
+	AttrMethod    = attribute.Key("prisma.method")
+	AttrModel     = attribute.Key("prisma.model")
+	AttrEngine    = attribute.Key("prisma.engine")
+	AttrBatchSize = attribute.Key("prisma.batch_size")
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures the telemetry
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider. Defaults to the global tracer provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the meter provider. Defaults to the global meter provider.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// Telemetry records spans and metrics for all requests sent via a client
+type Telemetry struct {
+	tracer         trace.Tracer
+	duration       metric.Float64Histogram
+	engineDuration metric.Float64Histogram
+	errors         metric.Int64Counter
+}
+
+// New creates the instruments used to trace and measure requests
+func New(opts ...Option) (*Telemetry, error) {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	meter := c.meterProvider.Meter(instrumentationName)
+
+	duration, err := meter.Float64Histogram(
+		MetricDuration,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of Prisma client operations, including building the query and unmarshaling the result"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create duration histogram: %w", err)
+	}
+
+	engineDuration, err := meter.Float64Histogram(
+		MetricEngineDuration,
+		metric.WithUnit("s"),
+		metric.WithDescription("Time the query engine reported to spend on Prisma client operations"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create engine duration histogram: %w", err)
+	}
+
+	errorCount, err := meter.Int64Counter(
+		MetricErrors,
+		metric.WithDescription("Number of failed Prisma client operations"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create error counter: %w", err)
+	}
+
+	return &Telemetry{
+		tracer:         c.tracerProvider.Tracer(instrumentationName),
+		duration:       duration,
+		engineDuration: engineDuration,
+		errors:         errorCount,
+	}, nil
+}
+
+// Middleware returns a middleware which records a span per operation, with child spans for the
+// http request to the query engine and the time the engine reported to spend on it, and which
+// records latency and error metrics.
+func (t *Telemetry) Middleware() builder.Middleware {
+	return func(next builder.Handler) builder.Handler {
+		return func(ctx context.Context, req *builder.Request) error {
+			attrs := attributes(req)
+			start := time.Now()
+
+			ctx, span := t.tracer.Start(ctx, SpanOperation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			ctx = engine.WithRequestTrace(ctx, &engine.RequestTrace{
+				RoundTrip: func(start time.Time, end time.Time, elapsed time.Duration, err error) {
+					t.roundTrip(ctx, attrs, start, end, elapsed, err)
+				},
+			})
+
+			err := next(ctx, req)
+
+			t.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+
+			if err != nil && !errors.Is(err, types.ErrNotFound) {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				t.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+			}
+
+			return err
+		}
+	}
+}
+
+func (t *Telemetry) roundTrip(ctx context.Context, attrs []attribute.KeyValue, start time.Time, end time.Time, elapsed time.Duration, err error) {
+	ctx, span := t.tracer.Start(ctx, SpanEngineHTTP,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if elapsed > 0 {
+		// the engine only reports its duration, so assume it finished right before responding
+		_, engineSpan := t.tracer.Start(ctx, SpanEngine,
+			trace.WithSpanKind(trace.SpanKindInternal),
+			trace.WithTimestamp(end.Add(-elapsed)),
+		)
+		engineSpan.End(trace.WithTimestamp(end))
+
+		t.engineDuration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(attrs...))
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
+
+func attributes(req *builder.Request) []attribute.KeyValue {
+	if req.IsBatch() {
+		attrs := []attribute.KeyValue{
+			AttrOperation.String("batch"),
+			AttrMethod.String("transaction"),
+			AttrBatchSize.Int(len(req.Batch)),
+		}
+		if len(req.Batch) > 0 && req.Batch[0].Engine != nil {
+			attrs = append(attrs, AttrEngine.String(req.Batch[0].Engine.Name()))
+		}
+		return attrs
+	}
+
+	attrs := []attribute.KeyValue{
+		AttrOperation.String(req.Query.Operation),
+		AttrMethod.String(req.Query.Method),
+		AttrModel.String(req.Query.Model),
+	}
+	if req.Query.Engine != nil {
+		attrs = append(attrs, AttrEngine.String(req.Query.Engine.Name()))
+	}
+	return attrs
+}
@@ -0,0 +1,161 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+)
+
+type fakeEngine struct {
+	engine.Engine
+	middleware []builder.Middleware
+	err        error
+}
+
+func (e *fakeEngine) Name() string {
+	return "fake"
+}
+
+func (e *fakeEngine) Middleware() []builder.Middleware {
+	return e.middleware
+}
+
+func (e *fakeEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	if trace := engine.RequestTraceFromContext(ctx); trace != nil {
+		end := time.Now()
+		trace.RoundTrip(end.Add(-10*time.Millisecond), end, 4*time.Millisecond, e.err)
+	}
+	if e.err != nil {
+		return e.err
+	}
+	return json.Unmarshal([]byte(`{"id":"a"}`), v)
+}
+
+func TestTelemetry(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantErrors int64
+	}{{
+		name: "success",
+	}, {
+		name:       "error",
+		err:        errFailed,
+		wantErrors: 1,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+			reader := sdkmetric.NewManualReader()
+			mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+			tel, err := New(WithTracerProvider(tp), WithMeterProvider(mp))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e := &fakeEngine{
+				middleware: []builder.Middleware{tel.Middleware()},
+				err:        tt.err,
+			}
+
+			q := builder.NewQuery()
+			q.Engine = e
+			q.Operation = "query"
+			q.Method = "findUnique"
+			q.Model = "User"
+			q.Outputs = []builder.Output{{Name: "id"}}
+
+			var v map[string]interface{}
+			if err := q.Exec(context.Background(), &v); !errors.Is(err, tt.err) {
+				t.Fatalf("Exec() error = %v, want %v", err, tt.err)
+			}
+
+			// spans are exported when they end, so children come first
+			got := spans.GetSpans()
+			if len(got) != 3 {
+				t.Fatalf("got %d spans, want 3", len(got))
+			}
+			engineSpan, httpSpan, operation := got[0], got[1], got[2]
+
+			if operation.Name != SpanOperation || httpSpan.Name != SpanEngineHTTP || engineSpan.Name != SpanEngine {
+				t.Fatalf("got spans %s, %s, %s", operation.Name, httpSpan.Name, engineSpan.Name)
+			}
+			if httpSpan.Parent.SpanID() != operation.SpanContext.SpanID() {
+				t.Errorf("http span is not a child of the operation span")
+			}
+			if engineSpan.Parent.SpanID() != httpSpan.SpanContext.SpanID() {
+				t.Errorf("engine span is not a child of the http span")
+			}
+			if d := engineSpan.EndTime.Sub(engineSpan.StartTime); d != 4*time.Millisecond {
+				t.Errorf("engine span duration = %s, want 4ms", d)
+			}
+
+			wantAttrs := map[string]string{
+				string(AttrOperation): "query",
+				string(AttrMethod):    "findUnique",
+				string(AttrModel):     "User",
+				string(AttrEngine):    "fake",
+			}
+			for _, attr := range operation.Attributes {
+				if want, ok := wantAttrs[string(attr.Key)]; ok && attr.Value.AsString() != want {
+					t.Errorf("attribute %s = %s, want %s", attr.Key, attr.Value.AsString(), want)
+				}
+			}
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+
+			// decode via json, as the metric data types are generic
+			type dataPoints struct {
+				DataPoints []struct {
+					Count uint64
+					Value int64
+				}
+			}
+			metrics := map[string]dataPoints{}
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					raw, err := json.Marshal(m.Data)
+					if err != nil {
+						t.Fatal(err)
+					}
+					var data dataPoints
+					if err := json.Unmarshal(raw, &data); err != nil {
+						t.Fatal(err)
+					}
+					metrics[m.Name] = data
+				}
+			}
+
+			for _, name := range []string{MetricDuration, MetricEngineDuration} {
+				if h := metrics[name]; len(h.DataPoints) != 1 || h.DataPoints[0].Count != 1 {
+					t.Errorf("expected one %s measurement, got %+v", name, h)
+				}
+			}
+
+			var errorCount int64
+			for _, dp := range metrics[MetricErrors].DataPoints {
+				errorCount += dp.Value
+			}
+			if errorCount != tt.wantErrors {
+				t.Errorf("error count = %d, want %d", errorCount, tt.wantErrors)
+			}
+		})
+	}
+}
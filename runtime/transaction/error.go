@@ -0,0 +1,26 @@
+package transaction
+
+import (
+	"fmt"
+)
+
+// BatchError is returned when a query of a batch transaction fails.
+// It reports which query failed, and wraps the query engine error, so it can be inspected using errors.Is and errors.As.
+type BatchError struct {
+	// Index is the position of the failing query in the transaction, starting at 0
+	Index int
+	// Query is the name of the failing operation, e.g. updateOneUser
+	Query string
+	// Model is the name of the model of the failing operation
+	Model string
+	// Err is the error returned by the query engine, or the error of a query which could not be built
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("transaction query %d (%s) failed: %s", e.Index, e.Query, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
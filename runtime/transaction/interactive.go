@@ -0,0 +1,117 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+const (
+	// DefaultMaxWait is the default maximum amount of time to wait for an interactive transaction to start
+	DefaultMaxWait = 2 * time.Second
+	// DefaultTimeout is the default maximum amount of time an interactive transaction can run
+	DefaultTimeout = 5 * time.Second
+)
+
+// TxOption configures an interactive transaction
+type TxOption func(*engine.TxOptions)
+
+// WithMaxWait sets the maximum amount of time to wait for the transaction to start. Defaults to 2 seconds.
+func WithMaxWait(d time.Duration) TxOption {
+	return func(o *engine.TxOptions) {
+		o.MaxWait = d
+	}
+}
+
+// WithTimeout sets the maximum amount of time the transaction can run before it is rolled back
+// by the query engine. Defaults to 5 seconds.
+func WithTimeout(d time.Duration) TxOption {
+	return func(o *engine.TxOptions) {
+		o.Timeout = d
+	}
+}
+
+// Run runs fn within an interactive transaction. All queries sent via the engine passed to fn
+// are executed within the transaction. The transaction is committed if fn returns nil, and
+// rolled back if fn returns an error or panics.
+func (r TX) Run(ctx context.Context, fn func(e engine.Engine) error, opts ...TxOption) (err error) {
+	if _, ok := r.Engine.(*txEngine); ok {
+		return fmt.Errorf("interactive transactions can not be nested")
+	}
+
+	e, ok := r.Engine.(engine.Transactional)
+	if !ok {
+		return fmt.Errorf("interactive transactions are not supported by this engine")
+	}
+
+	options := engine.TxOptions{
+		MaxWait: DefaultMaxWait,
+		Timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	id, err := e.StartTx(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	log := engine.Logger(r.Engine).With("transaction", id)
+	log.DebugContext(ctx, "started interactive transaction")
+
+	defer func() {
+		if p := recover(); p != nil {
+			if rollbackErr := e.RollbackTx(context.Background(), id); rollbackErr != nil {
+				log.Error("could not roll back transaction after panic", "error", rollbackErr)
+			}
+			panic(p)
+		}
+	}()
+
+	if err := fn(&txEngine{Engine: r.Engine, id: id}); err != nil {
+		if rollbackErr := e.RollbackTx(context.Background(), id); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %s)", err, rollbackErr)
+		}
+		log.DebugContext(ctx, "rolled back interactive transaction")
+		return err
+	}
+
+	if err := e.CommitTx(ctx, id); err != nil {
+		return err
+	}
+
+	log.DebugContext(ctx, "committed interactive transaction")
+
+	return nil
+}
+
+// txEngine sends all queries within the interactive transaction with the given id
+type txEngine struct {
+	engine.Engine
+	id string
+}
+
+func (e *txEngine) Connect() error {
+	return fmt.Errorf("can not connect within a transaction")
+}
+
+func (e *txEngine) Disconnect() error {
+	return fmt.Errorf("can not disconnect within a transaction")
+}
+
+// Logger returns the logger of the engine the transaction runs on
+func (e *txEngine) Logger() *slog.Logger {
+	return engine.Logger(e.Engine)
+}
+
+func (e *txEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	return e.Engine.Do(engine.WithTxID(ctx, e.id), payload, v)
+}
+
+func (e *txEngine) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	return e.Engine.Batch(engine.WithTxID(ctx, e.id), payload, v)
+}
@@ -0,0 +1,126 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+)
+
+type fakeEngine struct {
+	engine.Engine
+	opts    engine.TxOptions
+	txIDs   []string
+	actions []string
+}
+
+func (e *fakeEngine) Do(ctx context.Context, payload interface{}, v interface{}) error {
+	e.txIDs = append(e.txIDs, engine.TxID(ctx))
+	return nil
+}
+
+func (e *fakeEngine) StartTx(ctx context.Context, opts engine.TxOptions) (string, error) {
+	e.opts = opts
+	e.actions = append(e.actions, "start")
+	return "tx1", nil
+}
+
+func (e *fakeEngine) CommitTx(ctx context.Context, id string) error {
+	e.actions = append(e.actions, "commit "+id)
+	return nil
+}
+
+func (e *fakeEngine) RollbackTx(ctx context.Context, id string) error {
+	e.actions = append(e.actions, "rollback "+id)
+	return nil
+}
+
+func TestTX_Run(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		fn      func(e engine.Engine) error
+		opts    []TxOption
+		panics  bool
+		wantErr error
+		want    []string
+	}{{
+		name: "commit",
+		fn: func(e engine.Engine) error {
+			return e.Do(context.Background(), nil, nil)
+		},
+		want: []string{"start", "commit tx1"},
+	}, {
+		name: "rollback on error",
+		fn: func(e engine.Engine) error {
+			return errFailed
+		},
+		wantErr: errFailed,
+		want:    []string{"start", "rollback tx1"},
+	}, {
+		name: "rollback on panic",
+		fn: func(e engine.Engine) error {
+			panic("boom")
+		},
+		panics: true,
+		want:   []string{"start", "rollback tx1"},
+	}, {
+		name: "nested",
+		fn: func(e engine.Engine) error {
+			return TX{Engine: e}.Run(context.Background(), func(engine.Engine) error {
+				return nil
+			})
+		},
+		wantErr: errors.New("interactive transactions can not be nested"),
+		want:    []string{"start", "rollback tx1"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &fakeEngine{}
+			var err error
+			func() {
+				defer func() {
+					if p := recover(); (p != nil) != tt.panics {
+						t.Errorf("Run() panic = %v, want panic %v", p, tt.panics)
+					}
+				}()
+				err = TX{Engine: e}.Run(context.Background(), tt.fn, tt.opts...)
+			}()
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(e.actions) != len(tt.want) {
+				t.Fatalf("Run() actions = %v, want %v", e.actions, tt.want)
+			}
+			for i := range tt.want {
+				if e.actions[i] != tt.want[i] {
+					t.Errorf("Run() actions = %v, want %v", e.actions, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTX_Run_options(t *testing.T) {
+	e := &fakeEngine{}
+	err := TX{Engine: e}.Run(context.Background(), func(tx engine.Engine) error {
+		if err := tx.Do(context.Background(), nil, nil); err != nil {
+			return err
+		}
+		return nil
+	}, WithTimeout(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := engine.TxOptions{MaxWait: DefaultMaxWait, Timeout: 10 * time.Second}
+	if e.opts != want {
+		t.Errorf("Run() options = %+v, want %+v", e.opts, want)
+	}
+	if len(e.txIDs) != 1 || e.txIDs[0] != "tx1" {
+		t.Errorf("Run() tx ids = %v, want [tx1]", e.txIDs)
+	}
+}
@@ -0,0 +1,13 @@
+package transaction
+
+// IsolationLevel describes the isolation level of a transaction
+type IsolationLevel string
+
+// IsolationLevel values; not every database supports every level
+const (
+	ReadUncommitted IsolationLevel = "ReadUncommitted"
+	ReadCommitted   IsolationLevel = "ReadCommitted"
+	RepeatableRead  IsolationLevel = "RepeatableRead"
+	Snapshot        IsolationLevel = "Snapshot"
+	Serializable    IsolationLevel = "Serializable"
+)
@@ -23,7 +23,7 @@ func (r *Result) Get(c <-chan []byte, v interface{}) error {
 		res = data
 		r.cache = data
 	}
-	logger.Debug.Printf("tx result: %s", res)
+	logger.Default().Debug("tx result", "result", string(res))
 	if err := json.Unmarshal(res, &v); err != nil {
 		return err
 	}
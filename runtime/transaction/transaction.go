@@ -10,6 +10,8 @@ import (
 
 type TX struct {
 	Engine engine.Engine
+	// IsolationLevels contains the isolation levels supported by the database provider at generate time
+	IsolationLevels []IsolationLevel
 }
 
 type Param interface {
@@ -18,24 +20,31 @@ type Param interface {
 }
 
 func (r TX) Transaction(queries ...Param) Exec {
-	requests := make([]engine.GQLRequest, len(queries))
-	for i, query := range queries {
-		requests[i] = engine.GQLRequest{
-			Query:     query.ExtractQuery().Build(),
-			Variables: map[string]interface{}{},
-		}
-	}
 	return Exec{
-		engine:   r.Engine,
-		requests: requests,
-		queries:  queries,
+		engine:          r.Engine,
+		queries:         queries,
+		isolationLevels: r.IsolationLevels,
 	}
 }
 
 type Exec struct {
-	queries  []Param
-	engine   engine.Engine
-	requests []engine.GQLRequest
+	queries         []Param
+	engine          engine.Engine
+	isolation       IsolationLevel
+	isolationLevels []IsolationLevel
+}
+
+// WithIsolation sets the isolation level of the transaction.
+// Exec returns an error if the isolation level is not supported by the database. This check uses the provider
+// the client was generated for, so a client used with a different database at runtime, e.g. via a datasource
+// URL override, relies on the query engine to reject the isolation level instead.
+//
+// Example:
+//
+//	err := client.Prisma.Transaction(a, b).WithIsolation(transaction.Serializable).Exec(ctx)
+func (r Exec) WithIsolation(level IsolationLevel) Exec {
+	r.isolation = level
+	return r
 }
 
 func (r Exec) Exec(ctx context.Context) error {
@@ -44,25 +53,95 @@ func (r Exec) Exec(ctx context.Context) error {
 		defer close(q.ExtractQuery().TxResult)
 	}
 
+	if r.isolation != "" && !r.supports(r.isolation) {
+		return fmt.Errorf("isolation level %s is not supported by this database; supported levels: %v", r.isolation, r.isolationLevels)
+	}
+
+	batch := make([]*builder.Query, len(r.queries))
+	for i, q := range r.queries {
+		query := q.ExtractQuery()
+		batch[i] = &query
+	}
+	for i, query := range batch {
+		if query.Err != nil {
+			return r.batchError(batch, i, query.Err)
+		}
+	}
+
 	var result engine.GQLBatchResponse
-	payload := engine.GQLBatchRequest{
-		Batch:       r.requests,
-		Transaction: true,
+	req := &builder.Request{
+		Batch:  batch,
+		Result: &result,
 	}
-	if err := r.engine.Batch(ctx, payload, &result); err != nil {
-		return fmt.Errorf("could not send raw query: %w", err)
+	err := builder.Intercept(ctx, r.engine, req, func(ctx context.Context, req *builder.Request) error {
+		requests := make([]engine.GQLRequest, len(req.Batch))
+		for i, query := range req.Batch {
+			requests[i] = engine.GQLRequest{
+				Query:     query.Build(),
+				Variables: map[string]interface{}{},
+			}
+		}
+		payload := engine.GQLBatchRequest{
+			Batch:          requests,
+			Transaction:    true,
+			IsolationLevel: string(r.isolation),
+		}
+		if err := r.engine.Batch(ctx, payload, req.Result); err != nil {
+			return fmt.Errorf("could not send raw query: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	if len(result.Errors) > 0 {
 		first := result.Errors[0]
-		return fmt.Errorf("pql error: %s", first.RawMessage())
+		// the query engine reports which query of the batch failed via meta.batch_request_idx
+		if first.UserFacingError != nil {
+			if idx, ok := first.UserFacingError.Meta["batch_request_idx"].(float64); ok {
+				return r.batchError(req.Batch, int(idx), first.Err())
+			}
+		}
+		return first.Err()
 	}
 	for i, inner := range result.Result {
 		if len(inner.Errors) > 0 {
-			first := result.Errors[0]
-			return fmt.Errorf("pql error: %s", first.RawMessage())
+			first := inner.Errors[0]
+			return r.batchError(req.Batch, i, first.Err())
+		}
+		if i >= len(req.Batch) {
+			break
 		}
 
-		r.queries[i].ExtractQuery().TxResult <- inner.Data.Result
+		// middleware may have added queries without a result channel, or the same query twice
+		select {
+		case req.Batch[i].TxResult <- inner.Data.Result:
+		default:
+		}
 	}
 	return nil
 }
+
+// batchError returns an error for the query at index i of the batch as it was sent, which middleware may have
+// changed
+func (r Exec) batchError(batch []*builder.Query, i int, err error) error {
+	if i < 0 || i >= len(batch) {
+		return err
+	}
+	q := batch[i]
+	return &BatchError{
+		Index: i,
+		Query: q.Method + q.Model,
+		Model: q.Model,
+		Err:   err,
+	}
+}
+
+func (r Exec) supports(level IsolationLevel) bool {
+	for _, l := range r.isolationLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
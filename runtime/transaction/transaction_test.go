@@ -0,0 +1,346 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
+)
+
+type batchEngine struct {
+	engine.Engine
+	payload    engine.GQLBatchRequest
+	response   engine.GQLBatchResponse
+	middleware []builder.Middleware
+}
+
+func (e *batchEngine) Middleware() []builder.Middleware {
+	return e.middleware
+}
+
+func (e *batchEngine) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	e.payload = payload.(engine.GQLBatchRequest)
+	*v.(*engine.GQLBatchResponse) = e.response
+	return nil
+}
+
+type param struct {
+	query builder.Query
+}
+
+func (p param) IsTx() {}
+
+func (p param) ExtractQuery() builder.Query {
+	return p.query
+}
+
+func TestExec_WithIsolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		levels  []IsolationLevel
+		level   IsolationLevel
+		want    string
+		wantErr bool
+	}{{
+		name:   "default",
+		levels: []IsolationLevel{Serializable},
+	}, {
+		name:   "supported",
+		levels: []IsolationLevel{ReadCommitted, Serializable},
+		level:  Serializable,
+		want:   "Serializable",
+	}, {
+		name:    "unsupported",
+		levels:  []IsolationLevel{Serializable},
+		level:   RepeatableRead,
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &batchEngine{}
+			query := builder.NewQuery()
+			query.TxResult = make(chan []byte, 1)
+
+			err := TX{Engine: e, IsolationLevels: tt.levels}.Transaction(param{query}).WithIsolation(tt.level).Exec(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Exec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if e.payload.IsolationLevel != tt.want {
+				t.Errorf("Exec() isolation level = %q, want %q", e.payload.IsolationLevel, tt.want)
+			}
+		})
+	}
+}
+
+func TestExec_BatchError(t *testing.T) {
+	notFound := engine.GQLError{
+		Message: "Record to update not found.",
+		UserFacingError: &engine.UserFacingError{
+			Message:   "Record to update not found.",
+			ErrorCode: "P2025",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		response engine.GQLBatchResponse
+		want     *BatchError
+	}{{
+		name: "inner error",
+		response: engine.GQLBatchResponse{
+			Result: []engine.GQLResponse{{
+				Data: engine.Data{Result: json.RawMessage(`{"id":"a"}`)},
+			}, {
+				Errors: []engine.GQLError{notFound},
+			}},
+		},
+		want: &BatchError{
+			Index: 1,
+			Query: "updateOneUser",
+			Model: "User",
+		},
+	}, {
+		name: "outer error with batch index",
+		response: engine.GQLBatchResponse{
+			Errors: []engine.GQLError{{
+				UserFacingError: &engine.UserFacingError{
+					Message:   "Record to update not found.",
+					ErrorCode: "P2025",
+					Meta:      map[string]interface{}{"batch_request_idx": float64(1)},
+				},
+			}},
+		},
+		want: &BatchError{
+			Index: 1,
+			Query: "updateOneUser",
+			Model: "User",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &batchEngine{response: tt.response}
+
+			create := builder.NewQuery()
+			create.Method = "createOne"
+			create.Model = "User"
+			create.TxResult = make(chan []byte, 1)
+
+			update := builder.NewQuery()
+			update.Method = "updateOne"
+			update.Model = "User"
+			update.TxResult = make(chan []byte, 1)
+
+			err := TX{Engine: e}.Transaction(param{create}, param{update}).Exec(context.Background())
+
+			var batchErr *BatchError
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("Exec() error = %v, want BatchError", err)
+			}
+			if batchErr.Index != tt.want.Index || batchErr.Query != tt.want.Query || batchErr.Model != tt.want.Model {
+				t.Errorf("Exec() error = %+v, want %+v", batchErr, tt.want)
+			}
+			if !errors.Is(err, types.ErrNotFound) {
+				t.Errorf("Exec() error = %v, want ErrNotFound", err)
+			}
+			if _, ok := types.CheckErrorCode(err, types.ErrCodeRecordNotFound); !ok {
+				t.Errorf("Exec() error = %v, want error code P2025", err)
+			}
+
+			// results of queries before the failing one are still readable
+			if len(tt.response.Result) > 0 {
+				var v map[string]interface{}
+				if err := (&Result{}).Get(create.TxResult, &v); err != nil {
+					t.Fatal(err)
+				}
+				if v["id"] != "a" {
+					t.Errorf("Result() = %v, want id a", v)
+				}
+			}
+		})
+	}
+}
+
+func TestExec_invalidQuery(t *testing.T) {
+	e := &batchEngine{}
+
+	create := builder.NewQuery()
+	create.Method = "createOne"
+	create.Model = "User"
+	create.TxResult = make(chan []byte, 1)
+
+	invalid := errors.New("invalid query")
+	groupBy := builder.NewQuery()
+	groupBy.Method = "groupBy"
+	groupBy.Model = "User"
+	groupBy.TxResult = make(chan []byte, 1)
+	groupBy.Err = invalid
+
+	err := TX{Engine: e}.Transaction(param{create}, param{groupBy}).Exec(context.Background())
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("Exec() error = %v, want BatchError", err)
+	}
+	if batchErr.Index != 1 || batchErr.Query != "groupByUser" {
+		t.Errorf("Exec() error = %+v, want index 1 and query groupByUser", batchErr)
+	}
+	if !errors.Is(err, invalid) {
+		t.Errorf("Exec() error = %v, want %v", err, invalid)
+	}
+	if e.payload.Batch != nil {
+		t.Errorf("Exec() sent %v, want no request", e.payload.Batch)
+	}
+}
+
+func TestExec_middleware(t *testing.T) {
+	var methods []string
+	e := &batchEngine{
+		middleware: []builder.Middleware{func(next builder.Handler) builder.Handler {
+			return func(ctx context.Context, req *builder.Request) error {
+				if !req.IsBatch() {
+					t.Errorf("expected batch request")
+				}
+				for _, q := range req.Batch {
+					methods = append(methods, q.Method+q.Model)
+				}
+				return next(ctx, req)
+			}
+		}},
+	}
+
+	create := builder.NewQuery()
+	create.Operation = "mutation"
+	create.Method = "createOne"
+	create.Model = "User"
+	create.TxResult = make(chan []byte, 1)
+
+	if err := (TX{Engine: e}).Transaction(param{create}).Exec(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(methods) != 1 || methods[0] != "createOneUser" {
+		t.Errorf("middleware saw %v, want [createOneUser]", methods)
+	}
+	if len(e.payload.Batch) != 1 {
+		t.Errorf("Exec() sent %d queries, want 1", len(e.payload.Batch))
+	}
+}
+
+// echoEngine returns the built query of each query of a batch as its result
+type echoEngine struct {
+	engine.Engine
+	middleware []builder.Middleware
+}
+
+func (e *echoEngine) Middleware() []builder.Middleware {
+	return e.middleware
+}
+
+func (e *echoEngine) Batch(ctx context.Context, payload interface{}, v interface{}) error {
+	var response engine.GQLBatchResponse
+	for _, q := range payload.(engine.GQLBatchRequest).Batch {
+		result, err := json.Marshal(q.Query)
+		if err != nil {
+			return err
+		}
+		var inner engine.GQLResponse
+		inner.Data.Result = result
+		response.Result = append(response.Result, inner)
+	}
+	*v.(*engine.GQLBatchResponse) = response
+	return nil
+}
+
+func TestExec_middlewareChangesBatch(t *testing.T) {
+	extra := builder.NewQuery()
+	extra.Operation = "mutation"
+	extra.Method = "deleteMany"
+	extra.Model = "Log"
+
+	tests := []struct {
+		name   string
+		change func(batch []*builder.Query) []*builder.Query
+		// want contains the method each query receives the result of; empty if it receives none
+		want []string
+	}{{
+		name: "append",
+		change: func(batch []*builder.Query) []*builder.Query {
+			return append(batch, &extra)
+		},
+		want: []string{"createOne", "updateOne"},
+	}, {
+		name: "prepend",
+		change: func(batch []*builder.Query) []*builder.Query {
+			return append([]*builder.Query{&extra}, batch...)
+		},
+		want: []string{"createOne", "updateOne"},
+	}, {
+		name: "drop",
+		change: func(batch []*builder.Query) []*builder.Query {
+			return batch[1:]
+		},
+		want: []string{"", "updateOne"},
+	}, {
+		name: "reorder",
+		change: func(batch []*builder.Query) []*builder.Query {
+			return []*builder.Query{batch[1], batch[0]}
+		},
+		want: []string{"createOne", "updateOne"},
+	}, {
+		name: "duplicate",
+		change: func(batch []*builder.Query) []*builder.Query {
+			return append(batch, batch[0])
+		},
+		want: []string{"createOne", "updateOne"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &echoEngine{
+				middleware: []builder.Middleware{func(next builder.Handler) builder.Handler {
+					return func(ctx context.Context, req *builder.Request) error {
+						req.Batch = tt.change(req.Batch)
+						return next(ctx, req)
+					}
+				}},
+			}
+
+			create := builder.NewQuery()
+			create.Operation = "mutation"
+			create.Method = "createOne"
+			create.Model = "User"
+			create.TxResult = make(chan []byte, 1)
+
+			update := builder.NewQuery()
+			update.Operation = "mutation"
+			update.Method = "updateOne"
+			update.Model = "User"
+			update.TxResult = make(chan []byte, 1)
+
+			if err := (TX{Engine: e}).Transaction(param{create}, param{update}).Exec(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, q := range []builder.Query{create, update} {
+				var query string
+				err := (&Result{}).Get(q.TxResult, &query)
+				if tt.want[i] == "" {
+					if err == nil {
+						t.Errorf("query %d: expected no result, got %s", i, query)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("query %d: %s", i, err)
+				}
+				if !strings.Contains(query, tt.want[i]) {
+					t.Errorf("query %d: got the result of %s, want %s", i, query, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,152 @@
 package types
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrNotFound gets returned when a database record does not exist
 var ErrNotFound = errors.New("ErrNotFound")
+
+// ErrorCode is a Prisma query engine error code.
+// See https://www.prisma.io/docs/reference/api-reference/error-reference for all codes.
+type ErrorCode string
+
+// ErrorCode values
+const (
+	ErrCodeValueTooLong         ErrorCode = "P2000"
+	ErrCodeUniqueConstraint     ErrorCode = "P2002"
+	ErrCodeForeignKeyConstraint ErrorCode = "P2003"
+	ErrCodeNullConstraint       ErrorCode = "P2011"
+	ErrCodeRelationViolation    ErrorCode = "P2014"
+	ErrCodeRecordNotFound       ErrorCode = "P2025"
+	ErrCodeTransactionConflict  ErrorCode = "P2034"
+)
+
+// Error is an error returned by the query engine which contains an error code.
+// Use errors.As to access it.
+type Error struct {
+	// Code is the Prisma error code, e.g. P2002
+	Code ErrorCode
+	// Message is the human-readable error message
+	Message string
+	// Meta contains additional information depending on the error code
+	Meta map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Is reports whether the error is ErrNotFound, which is the case for P2025
+func (e *Error) Is(target error) bool {
+	return target == ErrNotFound && e.Code == ErrCodeRecordNotFound
+}
+
+// Target returns the fields or the name of the constraint which caused the error, if any
+func (e *Error) Target() []string {
+	switch target := e.Meta["target"].(type) {
+	case string:
+		return []string{target}
+	case []interface{}:
+		var fields []string
+		for _, field := range target {
+			if s, ok := field.(string); ok {
+				fields = append(fields, s)
+			}
+		}
+		return fields
+	}
+	return nil
+}
+
+// UniqueConstraintError is returned when a unique constraint is violated (P2002)
+type UniqueConstraintError struct {
+	Err *Error
+	// Fields contains the fields of the violated unique constraint.
+	// Some databases such as MySQL only report the name of the constraint.
+	Fields []string
+}
+
+func (e *UniqueConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UniqueConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// ForeignKeyConstraintError is returned when a foreign key constraint is violated (P2003)
+type ForeignKeyConstraintError struct {
+	Err *Error
+	// Field contains the foreign key field or the name of the violated constraint
+	Field string
+}
+
+func (e *ForeignKeyConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ForeignKeyConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// NewError returns the most specific error for the given error code.
+// Errors with P2025 match ErrNotFound via errors.Is.
+func NewError(code ErrorCode, message string, meta map[string]interface{}) error {
+	err := &Error{
+		Code:    code,
+		Message: message,
+		Meta:    meta,
+	}
+
+	switch code {
+	case ErrCodeUniqueConstraint:
+		return &UniqueConstraintError{
+			Err:    err,
+			Fields: err.Target(),
+		}
+	case ErrCodeForeignKeyConstraint:
+		field, _ := meta["field_name"].(string)
+		return &ForeignKeyConstraintError{
+			Err:   err,
+			Field: field,
+		}
+	}
+
+	return err
+}
+
+// CheckUniqueConstraint returns the UniqueConstraintError and true if err was caused by a unique constraint violation
+func CheckUniqueConstraint(err error) (*UniqueConstraintError, bool) {
+	var e *UniqueConstraintError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// CheckForeignKeyConstraint returns the ForeignKeyConstraintError and true if err was caused by a foreign key violation
+func CheckForeignKeyConstraint(err error) (*ForeignKeyConstraintError, bool) {
+	var e *ForeignKeyConstraintError
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// CheckErrorCode returns the Error and true if err was returned by the query engine with the given code.
+// ErrNotFound is reported as P2025.
+func CheckErrorCode(err error, code ErrorCode) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) && e.Code == code {
+		return e, true
+	}
+	if code == ErrCodeRecordNotFound && errors.Is(err, ErrNotFound) {
+		return &Error{
+			Code:    ErrCodeRecordNotFound,
+			Message: ErrNotFound.Error(),
+		}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     ErrorCode
+		meta     map[string]interface{}
+		notFound bool
+		check    func(t *testing.T, err error)
+	}{{
+		name:     "record not found",
+		code:     ErrCodeRecordNotFound,
+		notFound: true,
+		check: func(t *testing.T, err error) {
+			e, ok := CheckErrorCode(err, ErrCodeRecordNotFound)
+			if !ok {
+				t.Fatalf("expected error code P2025, got %v", err)
+			}
+			if e.Message != "message" {
+				t.Errorf("Message = %s, want message", e.Message)
+			}
+		},
+	}, {
+		name: "unique constraint",
+		code: ErrCodeUniqueConstraint,
+		meta: map[string]interface{}{"target": []interface{}{"email"}},
+		check: func(t *testing.T, err error) {
+			info, ok := CheckUniqueConstraint(err)
+			if !ok || len(info.Fields) != 1 || info.Fields[0] != "email" {
+				t.Errorf("expected unique constraint error on email, got %v", err)
+			}
+		},
+	}, {
+		name: "other code",
+		code: ErrCodeTransactionConflict,
+		check: func(t *testing.T, err error) {
+			if _, ok := CheckErrorCode(err, ErrCodeTransactionConflict); !ok {
+				t.Errorf("expected error code P2034, got %v", err)
+			}
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// errors are usually wrapped by the engine
+			err := fmt.Errorf("pql error: %w", NewError(tt.code, "message", tt.meta))
+			if errors.Is(err, ErrNotFound) != tt.notFound {
+				t.Errorf("errors.Is(err, ErrNotFound) = %t, want %t", !tt.notFound, tt.notFound)
+			}
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestCheckErrorCode_notFound(t *testing.T) {
+	e, ok := CheckErrorCode(ErrNotFound, ErrCodeRecordNotFound)
+	if !ok || e.Code != ErrCodeRecordNotFound {
+		t.Errorf("CheckErrorCode(ErrNotFound, P2025) = %v, %t, want P2025 error", e, ok)
+	}
+	if _, ok := CheckErrorCode(ErrNotFound, ErrCodeUniqueConstraint); ok {
+		t.Errorf("CheckErrorCode(ErrNotFound, P2002) = true, want false")
+	}
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/engine/replay"
+	"github.com/vnsoft2014/prisma-client-go/test"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestAggregate(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOnePost(data: {
+				id: "a",
+				title: "a",
+				views: 1,
+				score: 1.5,
+			}) {
+				id
+			}
+		}
+	`, `
+		mutation {
+			result: createOnePost(data: {
+				id: "b",
+				title: "b",
+				views: 2,
+			}) {
+				id
+			}
+		}
+	`, `
+		mutation {
+			result: createOnePost(data: {
+				id: "c",
+				title: "c",
+				views: 6,
+				score: 2.5,
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "count",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.Aggregate().Count().Count(Post.Score.Field()).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := &PostAggregateResult{
+				Count: &PostCountAggregate{
+					All: 3,
+					InnerPostCountAggregate: InnerPostCountAggregate{
+						Score: 2,
+					},
+				},
+			}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "sum avg min max",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.Aggregate().
+				Sum(Post.Views.Field()).
+				Avg(Post.Views.Field(), Post.Score.Field()).
+				Min(Post.Title.Field()).
+				Max(Post.Views.Field()).
+				Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			views := 9
+			avgViews := 3.0
+			avgScore := 2.0
+			minTitle := "a"
+			maxViews := 6
+
+			expected := &PostAggregateResult{
+				Sum: &PostSumAggregate{
+					Views: &views,
+				},
+				Avg: &PostAvgAggregate{
+					Views: &avgViews,
+					Score: &avgScore,
+				},
+				Min: &PostMinAggregate{
+					Title: &minTitle,
+				},
+				Max: &PostMaxAggregate{
+					Views: &maxViews,
+				},
+			}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "where",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.Aggregate(
+				Post.Views.Gte(2),
+			).Count().Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 2, actual.Count.All)
+		},
+	}, {
+		name:   "transaction",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			create := client.Post.CreateOne(
+				Post.Title.Set("d"),
+				Post.Views.Set(1),
+				Post.ID.Set("d"),
+			).Tx()
+
+			aggregate := client.Post.Aggregate().Count().Tx()
+
+			if err := client.Prisma.Transaction(create, aggregate).Exec(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 4, aggregate.Result().Count.All)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.MySQL, test.PostgreSQL, test.SQLite}, func(t *testing.T, db test.Database, ctx context.Context) {
+				// record with PRISMA_CLIENT_GO_REPLAY=record to replay the tests without databases
+				client := NewClient(WithEngine(replay.Wrap(replay.GoldenPath(t))))
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/test"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestCreateMany(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name: "create many",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			result, err := client.User.CreateMany(
+				[]UserSetScalarParam{
+					User.Email.Set("a"),
+					User.ID.Set("a"),
+				},
+				[]UserSetScalarParam{
+					User.Email.Set("b"),
+					User.ID.Set("b"),
+					User.Name.Set("b"),
+				},
+			).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, &BatchResult{Count: 2}, result)
+
+			actual, err := client.User.FindMany().OrderBy(User.ID.Order(ASC)).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			name := "b"
+			expected := []*UserModel{{
+				InnerUser: InnerUser{
+					ID:    "a",
+					Email: "a",
+				},
+			}, {
+				InnerUser: InnerUser{
+					ID:    "b",
+					Email: "b",
+					Name:  &name,
+				},
+			}}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name: "skip duplicates",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					id: "a",
+					email: "a",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			result, err := client.User.CreateMany(
+				[]UserSetScalarParam{
+					User.Email.Set("a"),
+					User.ID.Set("a"),
+				},
+				[]UserSetScalarParam{
+					User.Email.Set("b"),
+					User.ID.Set("b"),
+				},
+			).SkipDuplicates().Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, &BatchResult{Count: 1}, result)
+		},
+	}, {
+		name: "relation via foreign key",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					id: "a",
+					email: "a",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			result, err := client.Post.CreateMany(
+				[]PostSetScalarParam{
+					Post.Title.Set("a"),
+					Post.AuthorID.Set("a"),
+				},
+				[]PostSetScalarParam{
+					Post.Title.Set("b"),
+					Post.AuthorID.Set("a"),
+				},
+			).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 2, result.Count)
+
+			posts, err := client.Post.FindMany(Post.AuthorID.Equals("a")).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 2, len(posts))
+		},
+	}, {
+		name: "transaction",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			createMany := client.User.CreateMany(
+				[]UserSetScalarParam{
+					User.Email.Set("a"),
+					User.ID.Set("a"),
+				},
+				[]UserSetScalarParam{
+					User.Email.Set("b"),
+					User.ID.Set("b"),
+				},
+			).Tx()
+
+			count := client.User.Aggregate().Count().Tx()
+
+			if err := client.Prisma.Transaction(createMany, count).Exec(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 2, createMany.Result().Count)
+			assert.Equal(t, 2, count.Result().Count.All)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			// createMany is not supported on SQLite
+			test.RunSerial(t, []test.Database{test.MySQL, test.PostgreSQL}, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/test"
+)
+
+type cx = context.Context
+
+func TestDatasourceOverride(t *testing.T) {
+	// language=GraphQL
+	createA := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				email: "a",
+			}) {
+				id
+			}
+		}
+	`}
+
+	// language=GraphQL
+	createB := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "b",
+				email: "b",
+			}) {
+				id
+			}
+		}
+	`}
+
+	test.RunSerial(t, []test.Database{test.SQLite}, func(t *testing.T, db test.Database, ctx cx) {
+		// set up two database files with different records
+		clientA := NewClient()
+		mockDBA := test.Start(t, db, clientA.Engine, createA)
+		defer test.End(t, db, clientA.Engine, mockDBA)
+
+		clientB := NewClient()
+		mockDBB := test.Start(t, db, clientB.Engine, createB)
+		defer test.End(t, db, clientB.Engine, mockDBB)
+
+		// create clients in the same process which point to the existing files via datasource overrides;
+		// the schema of each of these clients points to a new, empty database file
+		overrideA := NewClient(WithDatasourceURL(db.ConnectionString(mockDBA)))
+		mockDBOverrideA := test.Start(t, db, overrideA.Engine, nil)
+		defer test.End(t, db, overrideA.Engine, mockDBOverrideA)
+
+		overrideB := NewClient(WithDatasourceURL(db.ConnectionString(mockDBB)))
+		mockDBOverrideB := test.Start(t, db, overrideB.Engine, nil)
+		defer test.End(t, db, overrideB.Engine, mockDBOverrideB)
+
+		usersA, err := overrideA.User.FindMany().Exec(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, []*UserModel{{
+			InnerUser: InnerUser{
+				ID:    "a",
+				Email: "a",
+			},
+		}}, usersA)
+
+		usersB, err := overrideB.User.FindMany().Exec(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, []*UserModel{{
+			InnerUser: InnerUser{
+				ID:    "b",
+				Email: "b",
+			},
+		}}, usersB)
+
+		// writes via an override client go to the overridden database only
+		if _, err := overrideA.User.CreateOne(User.Email.Set("c"), User.ID.Set("c")).Exec(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		countA, err := clientA.User.FindMany().Exec(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 2, len(countA))
+
+		countB, err := clientB.User.FindMany().Exec(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 1, len(countB))
+	})
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/dbtest"
+)
+
+type cx = context.Context
+
+func newTestClient(t *testing.T, opts ...dbtest.Option) *PrismaClient {
+	t.Helper()
+	return dbtest.New(t, "schema.prisma", func(url string) dbtest.Client {
+		return NewClient(WithDatasourceURL(url))
+	}, opts...).(*PrismaClient)
+}
+
+func TestDBTest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("isolated databases", func(t *testing.T) {
+		for _, name := range []string{"a", "b"} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				client := newTestClient(t)
+
+				// the email is unique, so the second test fails if the databases are shared
+				_, err := client.User.CreateOne(User.Email.Set("a@example.com")).Exec(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				users, err := client.User.FindMany().Exec(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, 1, len(users))
+			})
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		client := newTestClient(t, dbtest.WithRollback())
+
+		_, err := client.User.CreateOne(User.Email.Set("a@example.com")).Exec(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// batch transactions run within the test transaction, so they see its writes
+		create := client.User.CreateOne(User.Email.Set("b@example.com")).Tx()
+		count := client.User.FindMany().Delete().Tx()
+		if err := client.Prisma.Transaction(create, count).Exec(ctx); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 2, count.Result().Count)
+	})
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/test"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func str(v string) *string {
+	return &v
+}
+
+func integer(v int) *int {
+	return &v
+}
+
+func TestGroupBy(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOnePost(data: {
+				id: "a",
+				title: "a",
+				category: "news",
+				views: 1,
+			}) {
+				id
+			}
+		}
+	`, `
+		mutation {
+			result: createOnePost(data: {
+				id: "b",
+				title: "b",
+				category: "news",
+				views: 5,
+			}) {
+				id
+			}
+		}
+	`, `
+		mutation {
+			result: createOnePost(data: {
+				id: "c",
+				title: "c",
+				category: "sports",
+				views: 2,
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "group by with aggregations",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.GroupBy(
+				Post.Category.Field(),
+			).OrderBy(
+				Post.Category.Order(SortOrderAsc),
+			).Count().Sum(Post.Views.Field()).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []PostGroupByResult{{
+				InnerPostGroupByResult: InnerPostGroupByResult{Category: str("news")},
+				Count:                  &PostCountAggregate{All: 2},
+				Sum:                    &PostSumAggregate{Views: integer(6)},
+			}, {
+				InnerPostGroupByResult: InnerPostGroupByResult{Category: str("sports")},
+				Count:                  &PostCountAggregate{All: 1},
+				Sum:                    &PostSumAggregate{Views: integer(2)},
+			}}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "where and having",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.GroupBy(
+				Post.Category.Field(),
+			).Where(
+				Post.Views.Gt(1),
+			).Having(
+				Post.HavingSum(Post.Views.Field()).Gte(5),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []PostGroupByResult{{
+				InnerPostGroupByResult: InnerPostGroupByResult{Category: str("news")},
+			}}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "order by aggregation and paginate",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.Post.GroupBy(
+				Post.Category.Field(),
+			).OrderBy(
+				Post.OrderByCount(Post.Category.Field(), SortOrderDesc),
+			).Having(
+				Post.HavingCount(Post.ID.Field()).Gte(1),
+			).Take(1).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := []PostGroupByResult{{
+				InnerPostGroupByResult: InnerPostGroupByResult{Category: str("news")},
+			}}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "paginate without order by",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := client.Post.GroupBy(
+				Post.Category.Field(),
+			).Take(1).Exec(ctx)
+			assert.EqualError(t, err, "skip and take require an order by; use OrderBy")
+		},
+	}, {
+		name:   "paginate without order by in a transaction",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			groupBy := client.Post.GroupBy(
+				Post.Category.Field(),
+			).Skip(1).Tx()
+
+			err := client.Prisma.Transaction(groupBy).Exec(ctx)
+			assert.EqualError(t, err, "transaction query 0 (groupByPost) failed: skip and take require an order by; use OrderBy")
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.MySQL, test.PostgreSQL, test.SQLite}, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
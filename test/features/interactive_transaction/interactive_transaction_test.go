@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/runtime/transaction"
+	"github.com/vnsoft2014/prisma-client-go/test"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestInteractiveTransaction(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				email: "a",
+				balance: 100,
+			}) {
+				id
+			}
+		}
+	`, `
+		mutation {
+			result: createOneUser(data: {
+				id: "b",
+				email: "b",
+				balance: 0,
+			}) {
+				id
+			}
+		}
+	`}
+
+	transfer := func(ctx cx, tx *PrismaClient, amount int) error {
+		from, err := tx.User.FindUnique(User.ID.Equals("a")).Update(
+			User.Balance.Decrement(amount),
+		).Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		if from.Balance < 0 {
+			return errors.New("insufficient balance")
+		}
+
+		_, err = tx.User.FindUnique(User.ID.Equals("b")).Update(
+			User.Balance.Increment(amount),
+		).Exec(ctx)
+		return err
+	}
+
+	balances := func(t *testing.T, client *PrismaClient, ctx cx) []int {
+		users, err := client.User.FindMany().OrderBy(User.ID.Order(ASC)).Exec(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []int
+		for _, user := range users {
+			result = append(result, user.Balance)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "commit",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			err := client.Prisma.Tx(ctx, func(tx *PrismaClient) error {
+				return transfer(ctx, tx, 60)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, []int{40, 60}, balances(t, client, ctx))
+		},
+	}, {
+		name:   "rollback on error",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			err := client.Prisma.Tx(ctx, func(tx *PrismaClient) error {
+				return transfer(ctx, tx, 160)
+			})
+
+			assert.EqualError(t, err, "insufficient balance")
+			assert.Equal(t, []int{100, 0}, balances(t, client, ctx))
+		},
+	}, {
+		name:   "rollback on panic",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			assert.PanicsWithValue(t, "boom", func() {
+				_ = client.Prisma.Tx(ctx, func(tx *PrismaClient) error {
+					if err := transfer(ctx, tx, 60); err != nil {
+						return err
+					}
+					panic("boom")
+				})
+			})
+
+			assert.Equal(t, []int{100, 0}, balances(t, client, ctx))
+		},
+	}, {
+		name:   "timeout",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			err := client.Prisma.Tx(ctx, func(tx *PrismaClient) error {
+				if err := transfer(ctx, tx, 60); err != nil {
+					return err
+				}
+				time.Sleep(200 * time.Millisecond)
+				return nil
+			}, transaction.WithTimeout(100*time.Millisecond))
+
+			assert.Error(t, err)
+			assert.Equal(t, []int{100, 0}, balances(t, client, ctx))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/runtime/builder"
+	"github.com/vnsoft2014/prisma-client-go/test"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				email: "a",
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "record queries",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			var methods []string
+			client.Use(func(next builder.Handler) builder.Handler {
+				return func(ctx context.Context, req *builder.Request) error {
+					if req.IsBatch() {
+						methods = append(methods, "batch")
+					} else {
+						methods = append(methods, req.Query.Method+req.Query.Model)
+					}
+					return next(ctx, req)
+				}
+			})
+
+			if _, err := client.User.FindUnique(User.ID.Equals("a")).Exec(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			var users []UserModel
+			if err := client.Prisma.QueryRaw(`SELECT * FROM "User"`).Exec(ctx, &users); err != nil {
+				t.Fatal(err)
+			}
+
+			create := client.User.CreateOne(User.Email.Set("b")).Tx()
+			if err := client.Prisma.Transaction(create).Exec(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			err := client.Prisma.Tx(ctx, func(tx *PrismaClient) error {
+				_, err := tx.User.FindMany().Exec(ctx)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, []string{"findUniqueUser", "queryRaw", "batch", "findManyUser"}, methods)
+		},
+	}, {
+		name:   "modify inputs",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			// scope all findMany queries to the email "b"
+			client.Use(func(next builder.Handler) builder.Handler {
+				return func(ctx context.Context, req *builder.Request) error {
+					if !req.IsBatch() && req.Query.Method == "findMany" {
+						scope := builder.Field{
+							Name:  "email",
+							Value: "b",
+						}
+						found := false
+						for i, input := range req.Query.Inputs {
+							if input.Name == "where" {
+								req.Query.Inputs[i].Fields = append(input.Fields, scope)
+								found = true
+							}
+						}
+						if !found {
+							req.Query.Inputs = append(req.Query.Inputs, builder.Input{
+								Name:   "where",
+								Fields: []builder.Field{scope},
+							})
+						}
+					}
+					return next(ctx, req)
+				}
+			})
+
+			actual, err := client.User.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 0, len(actual))
+		},
+	}, {
+		name:   "short-circuit and wrap errors",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			errReadOnly := errors.New("read only")
+			client.Use(func(next builder.Handler) builder.Handler {
+				return func(ctx context.Context, req *builder.Request) error {
+					if !req.IsBatch() && req.Query.Operation == "mutation" {
+						return fmt.Errorf("%s%s: %w", req.Query.Method, req.Query.Model, errReadOnly)
+					}
+					return next(ctx, req)
+				}
+			})
+
+			_, err := client.User.CreateOne(User.Email.Set("b")).Exec(ctx)
+			assert.True(t, errors.Is(err, errReadOnly))
+
+			actual, err := client.User.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 1, len(actual))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.PostgreSQL}, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
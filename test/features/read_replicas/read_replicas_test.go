@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/test"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func ids(users []*UserModel) []string {
+	var result []string
+	for _, user := range users {
+		result = append(result, user.ID)
+	}
+	return result
+}
+
+func TestReadReplicas(t *testing.T) {
+	// language=GraphQL
+	createPrimary := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "primary",
+				email: "primary",
+			}) {
+				id
+			}
+		}
+	`}
+
+	// language=GraphQL
+	createReplica := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "replica",
+				email: "replica",
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name string
+		run  Func
+	}{{
+		name: "reads go to the replica",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []string{"replica"}, ids(users))
+
+			var raw []*UserModel
+			if err := client.Prisma.QueryRaw(`SELECT * FROM User`).Exec(ctx, &raw); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []string{"replica"}, ids(raw))
+		},
+	}, {
+		name: "use primary",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			users, err := client.User.FindMany().UsePrimary().Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []string{"primary"}, ids(users))
+
+			var raw []*UserModel
+			if err := client.Prisma.QueryRaw(`SELECT * FROM User`).UsePrimary().Exec(ctx, &raw); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []string{"primary"}, ids(raw))
+		},
+	}, {
+		name: "writes go to the primary",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			if _, err := client.User.CreateOne(User.Email.Set("new"), User.ID.Set("new")).Exec(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			create := client.User.CreateOne(User.Email.Set("tx"), User.ID.Set("tx")).Tx()
+			if err := client.Prisma.Transaction(create).Exec(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := client.Prisma.ExecuteRaw(`UPDATE User SET email = 'raw' WHERE id = 'primary'`).Exec(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := client.User.FindUnique(User.ID.Equals("new")).Exec(ctx)
+			assert.Equal(t, ErrNotFound, err)
+
+			users, err := client.User.FindMany().OrderBy(User.ID.Order(SortOrderAsc)).UsePrimary().Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, []string{"new", "primary", "tx"}, ids(users))
+			assert.Equal(t, "raw", users[1].Email)
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, []test.Database{test.SQLite}, func(t *testing.T, db test.Database, ctx context.Context) {
+				primary := NewClient()
+				mockDBPrimary := test.Start(t, db, primary.Engine, createPrimary)
+				defer test.End(t, db, primary.Engine, mockDBPrimary)
+
+				replica := NewClient()
+				mockDBReplica := test.Start(t, db, replica.Engine, createReplica)
+				defer test.End(t, db, replica.Engine, mockDBReplica)
+
+				client := NewClient(
+					WithDatasourceURL(db.ConnectionString(mockDBPrimary)),
+					WithReadReplicas(db.ConnectionString(mockDBReplica)),
+				)
+				mockDBName := test.Start(t, db, client.Engine, nil)
+				defer test.End(t, db, client.Engine, mockDBName)
+
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vnsoft2014/prisma-client-go/test"
+)
+
+type cx = context.Context
+type Func func(t *testing.T, client *PrismaClient, ctx cx)
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	// language=GraphQL
+	before := []string{`
+		mutation {
+			result: createOneUser(data: {
+				id: "a",
+				email: "a@example.com",
+				name: "a",
+				posts: {
+					create: [{
+						id: "post",
+						title: "title",
+						content: "content",
+					}],
+				},
+			}) {
+				id
+			}
+		}
+	`}
+
+	tests := []struct {
+		name   string
+		before []string
+		run    Func
+	}{{
+		name:   "select find unique",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.User.FindUnique(
+				User.ID.Equals("a"),
+			).Select(
+				User.ID.Field(),
+				User.Email.Field(),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := &UserModel{
+				InnerUser: InnerUser{
+					ID:    "a",
+					Email: "a@example.com",
+				},
+			}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "omit find many",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.User.FindMany().Omit(
+				User.Email.Field(),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			name := "a"
+			expected := []*UserModel{{
+				InnerUser: InnerUser{
+					ID:   "a",
+					Name: &name,
+				},
+			}}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name:   "select with relations",
+		before: before,
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			actual, err := client.User.FindFirst().Select(
+				User.ID.Field(),
+			).With(
+				User.Posts.Fetch().Select(
+					Post.Title.Field(),
+				),
+			).Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := &UserModel{
+				InnerUser: InnerUser{
+					ID: "a",
+				},
+				RelationsUser: RelationsUser{
+					Posts: []PostModel{{
+						InnerPost: InnerPost{
+							Title: "title",
+						},
+					}},
+				},
+			}
+
+			assert.Equal(t, expected, actual)
+		},
+	}, {
+		name: "is selected",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			query := client.User.FindMany().Select(User.ID.Field())
+
+			assert.True(t, query.IsSelected(User.ID.Field()))
+			assert.False(t, query.IsSelected(User.Email.Field()))
+		},
+	}}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+				client := NewClient()
+				mockDBName := test.Start(t, db, client.Engine, tt.before)
+				defer test.End(t, db, client.Engine, mockDBName)
+				tt.run(t, client, context.Background())
+			})
+		})
+	}
+}
@@ -2,10 +2,12 @@ package db
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/vnsoft2014/prisma-client-go/runtime/transaction"
 	"github.com/vnsoft2014/prisma-client-go/test"
 )
 
@@ -17,6 +19,7 @@ func TestTransaction(t *testing.T) {
 
 	tests := []struct {
 		name   string
+		dbs    []test.Database
 		before []string
 		run    Func
 	}{{
@@ -88,6 +91,15 @@ func TestTransaction(t *testing.T) {
 			err := client.Prisma.Transaction(a, b).Exec(ctx)
 			assert.Errorf(t, err, "should error")
 
+			var batchErr *transaction.BatchError
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("expected batch error but got %v", err)
+			}
+			assert.Equal(t, 0, batchErr.Index)
+			assert.Equal(t, "updateOneUser", batchErr.Query)
+			assert.Equal(t, "User", batchErr.Model)
+			assert.True(t, IsErrNotFound(err))
+
 			// make sure the existing record wasn't touched
 
 			actual, err := client.User.FindMany().Exec(ctx)
@@ -104,11 +116,49 @@ func TestTransaction(t *testing.T) {
 
 			assert.Equal(t, expected, actual)
 		},
+	}, {
+		name: "isolation level",
+		// the query engine rejects isolation levels for MongoDB
+		dbs: []test.Database{test.MySQL, test.PostgreSQL, test.SQLite},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			createUser := client.User.CreateOne(
+				User.Email.Set("a"),
+				User.ID.Set("a"),
+			).Tx()
+
+			if err := client.Prisma.Transaction(createUser).WithIsolation(transaction.Serializable).Exec(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "a", createUser.Result().ID)
+		},
+	}, {
+		name: "unsupported isolation level",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			createUser := client.User.CreateOne(
+				User.Email.Set("a"),
+				User.ID.Set("a"),
+			).Tx()
+
+			err := client.Prisma.Transaction(createUser).WithIsolation(transaction.Snapshot).Exec(ctx)
+			assert.Error(t, err)
+
+			actual, err := client.User.FindMany().Exec(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, 0, len(actual))
+		},
 	}}
 	for _, tt := range tests {
 		tt := tt
+		dbs := tt.dbs
+		if dbs == nil {
+			dbs = test.Databases
+		}
 		t.Run(tt.name, func(t *testing.T) {
-			test.RunSerial(t, test.Databases, func(t *testing.T, db test.Database, ctx context.Context) {
+			test.RunSerial(t, dbs, func(t *testing.T, db test.Database, ctx context.Context) {
 				client := NewClient()
 				mockDBName := test.Start(t, db, client.Engine, tt.before)
 				defer test.End(t, db, client.Engine, mockDBName)
@@ -2,9 +2,14 @@ package mock
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	prismamock "github.com/vnsoft2014/prisma-client-go/engine/mock"
+	"github.com/vnsoft2014/prisma-client-go/runtime/transaction"
 )
 
 func TestTypedMockReturns(t *testing.T) {
@@ -38,7 +43,7 @@ func TestTypedMockReturns(t *testing.T) {
 }
 
 func TestTypedMockReturnsMany(t *testing.T) {
-	do := func(ctx context.Context, client *PrismaClient) ([]UserModel, error) {
+	do := func(ctx context.Context, client *PrismaClient) ([]*UserModel, error) {
 		return client.User.FindMany(User.Name.Equals("foo")).Exec(ctx)
 	}
 
@@ -61,7 +66,7 @@ func TestTypedMockReturnsMany(t *testing.T) {
 
 	actual, err := do(context.Background(), client)
 	assert.Equal(t, expectedErr, err)
-	assert.Equal(t, expected, actual)
+	assert.Equal(t, []*UserModel{&expected[0]}, actual)
 }
 
 func TestMockError(t *testing.T) {
@@ -86,3 +91,173 @@ func TestMockError(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 	assert.Equal(t, true, actual == nil)
 }
+
+func TestMockTransaction(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+
+	expected := UserModel{
+		InnerUser: InnerUser{
+			ID:   "123",
+			Name: "foo",
+		},
+	}
+
+	mock.User.Expect(
+		client.User.CreateOne(User.ID.Set("123"), User.Name.Set("foo")),
+	).Returns(expected)
+	mock.Prisma.ExpectExecuteRaw(`UPDATE "User" SET name = $1`, "bar").Returns(2)
+	mock.Prisma.ExpectTransaction(
+		client.User.CreateOne(User.ID.Set("123"), User.Name.Set("foo")).Tx(),
+		client.Prisma.ExecuteRaw(`UPDATE "User" SET name = $1`, "bar").Tx(),
+	)
+
+	create := client.User.CreateOne(User.ID.Set("123"), User.Name.Set("foo")).Tx()
+	update := client.Prisma.ExecuteRaw(`UPDATE "User" SET name = $1`, "bar").Tx()
+	if err := client.Prisma.Transaction(create, update).Exec(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, &expected, create.Result())
+	assert.Equal(t, 2, update.Result().Count)
+}
+
+func TestMockTransactionError(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("123")).Update(User.Name.Set("foo")),
+	).Errors(ErrNotFound)
+	mock.Prisma.ExpectTransaction(
+		client.User.FindUnique(User.ID.Equals("123")).Update(User.Name.Set("foo")).Tx(),
+	)
+
+	update := client.User.FindUnique(User.ID.Equals("123")).Update(User.Name.Set("foo")).Tx()
+	err := client.Prisma.Transaction(update).Exec(context.Background())
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	var batchErr *transaction.BatchError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, 0, batchErr.Index)
+	assert.Equal(t, "updateOneUser", batchErr.Query)
+}
+
+func TestMockQueryRaw(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+
+	expected := []UserModel{{
+		InnerUser: InnerUser{
+			ID:   "123",
+			Name: "foo",
+		},
+	}}
+	mock.Prisma.ExpectQueryRaw(`SELECT * FROM "User" WHERE name = $1`, "foo").Returns(expected)
+
+	var actual []UserModel
+	if err := client.Prisma.QueryRaw(`SELECT * FROM "User" WHERE name = $1`, "foo").Exec(context.Background(), &actual); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestMockExecuteRaw(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+
+	mock.Prisma.ExpectExecuteRaw(`DELETE FROM "User" WHERE id = $1`, "123").Returns(1)
+
+	result, err := client.Prisma.ExecuteRaw(`DELETE FROM "User" WHERE id = $1`, "123").Exec(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, result.Count)
+}
+
+func TestMockTimes(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+
+	expected := UserModel{
+		InnerUser: InnerUser{
+			ID:   "123",
+			Name: "foo",
+		},
+	}
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("123")),
+	).Returns(expected).Times(2)
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("456")),
+	).Errors(ErrNotFound).Maybe()
+
+	for i := 0; i < 2; i++ {
+		actual, err := client.User.FindUnique(User.ID.Equals("123")).Exec(context.Background())
+		assert.Equal(t, nil, err)
+		assert.Equal(t, &expected, actual)
+	}
+}
+
+func TestMockInOrder(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+
+	mock.User.Expect(
+		client.User.CreateOne(User.ID.Set("123"), User.Name.Set("foo")),
+	).Returns(UserModel{InnerUser: InnerUser{ID: "123", Name: "foo"}}).InOrder()
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("123")).Delete(),
+	).Returns(UserModel{InnerUser: InnerUser{ID: "123", Name: "foo"}}).InOrder()
+
+	_, err := client.User.CreateOne(User.ID.Set("123"), User.Name.Set("foo")).Exec(context.Background())
+	assert.Equal(t, nil, err)
+	_, err = client.User.FindUnique(User.ID.Equals("123")).Delete().Exec(context.Background())
+	assert.Equal(t, nil, err)
+}
+
+func TestMockArg(t *testing.T) {
+	client, mock, ensure := NewMock()
+	defer ensure(t)
+
+	mock.User.Expect(
+		client.User.CreateOne(User.ID.Set(""), User.Name.Set("foo")),
+	).Returns(UserModel{InnerUser: InnerUser{ID: "generated", Name: "foo"}}).
+		Arg("data.id", prismamock.Any()).
+		Arg("data.name", prismamock.Match(func(v interface{}) bool {
+			return strings.HasPrefix(v.(string), "f")
+		}))
+
+	actual, err := client.User.CreateOne(User.ID.Set("a1b2"), User.Name.Set("fred")).Exec(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "generated", actual.ID)
+}
+
+func TestMockUnexpectedQuery(t *testing.T) {
+	client, mock, _ := NewMock()
+
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("123")),
+	).Returns(UserModel{})
+
+	_, err := client.User.FindUnique(User.ID.Equals("456")).Exec(context.Background())
+	assert.True(t, errors.Is(err, prismamock.ErrUnexpectedQuery))
+
+	mismatches := mock.Engine.Mismatches()
+	assert.Equal(t, 1, len(mismatches))
+	assert.Contains(t, mismatches[0], "- where.id = \"123\"\n+ where.id = \"456\"\n")
+}
+
+func TestNewMockT(t *testing.T) {
+	client, mock := NewMockT(t)
+
+	mock.User.Expect(
+		client.User.FindUnique(User.ID.Equals("123")),
+	).Returns(UserModel{
+		InnerUser: InnerUser{
+			ID: "123",
+		},
+	}).Times(1)
+
+	actual, err := client.User.FindUnique(User.ID.Equals("123")).Exec(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "123", actual.ID)
+}
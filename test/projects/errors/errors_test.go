@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/vnsoft2014/prisma-client-go/runtime/types"
 	"github.com/vnsoft2014/prisma-client-go/test"
 )
 
@@ -46,6 +47,50 @@ func TestBasic(t *testing.T) {
 
 			assert.Equal(t, ErrNotFound, err)
 		},
+	}, {
+		name: "unique constraint",
+		// language=GraphQL
+		before: []string{`
+			mutation {
+				result: createOneUser(data: {
+					id: "a",
+					email: "a",
+					username: "a",
+				}) {
+					id
+				}
+			}
+		`},
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := client.User.CreateOne(
+				User.Email.Set("a"),
+				User.Username.Set("b"),
+			).Exec(ctx)
+
+			info, ok := IsErrUniqueConstraint(err)
+			if !ok {
+				t.Fatalf("expected unique constraint error but got %v", err)
+			}
+
+			assert.Equal(t, types.ErrCodeUniqueConstraint, info.Err.Code)
+			assert.NotEmpty(t, info.Fields)
+
+			_, ok = IsErrCode(err, types.ErrCodeUniqueConstraint)
+			assert.True(t, ok)
+			assert.False(t, IsErrNotFound(err))
+		},
+	}, {
+		name: "not found helper",
+		run: func(t *testing.T, client *PrismaClient, ctx cx) {
+			_, err := client.User.FindUnique(
+				User.Email.Equals("404"),
+			).Delete().Exec(ctx)
+
+			assert.True(t, IsErrNotFound(err))
+
+			_, ok := IsErrUniqueConstraint(err)
+			assert.False(t, ok)
+		},
 	}}
 	for _, tt := range tests {
 		tt := tt
@@ -10,6 +10,7 @@ import (
 
 	"github.com/vnsoft2014/prisma-client-go/cli"
 	"github.com/vnsoft2014/prisma-client-go/engine"
+	"github.com/vnsoft2014/prisma-client-go/engine/replay"
 	"github.com/vnsoft2014/prisma-client-go/test/cmd"
 	"github.com/vnsoft2014/prisma-client-go/test/setup/mongodb"
 	"github.com/vnsoft2014/prisma-client-go/test/setup/mysql"
@@ -39,7 +40,24 @@ var Databases = []Database{
 const schemaTemplate = "schema.temp.%s.prisma"
 
 func replaceSchema(t *testing.T, db Database, e engine.Engine, schemaPath string, mockDB string) {
-	xe := e.(*engine.QueryEngine)
+	// recorded clients send requests to the wrapped engine
+	if r, ok := e.(*replay.Recorder); ok {
+		e = r.Engine
+	}
+
+	// replace the schema of all engines of a client with read replicas; replicas use datasource overrides,
+	// so only the schema of the primary is pushed
+	if re, ok := e.(*engine.ReplicaEngine); ok {
+		for _, replica := range re.Replicas {
+			replaceSchema(t, db, replica, schemaPath, mockDB)
+		}
+		e = re.Primary
+	}
+
+	xe, ok := e.(*engine.QueryEngine)
+	if !ok {
+		t.Fatalf("can't replace the schema of the %s engine; integration tests need a client which runs the query engine", e.Name())
+	}
 	xe.ReplaceSchema(func(schema string) string {
 		for _, fromDB := range Databases {
 			schema = strings.ReplaceAll(schema, fmt.Sprintf(`"%s"`, fromDB.Name()), fmt.Sprintf(`"%s"`, db.Name()))
@@ -55,6 +73,15 @@ func replaceSchema(t *testing.T, db Database, e engine.Engine, schemaPath string
 }
 
 func Start(t *testing.T, db Database, e engine.Engine, queries []string) string {
+	// replayed clients need neither a database nor a query engine; the queries are served from the golden file
+	if _, ok := e.(*replay.Replayer); ok {
+		if err := e.Connect(); err != nil {
+			t.Fatalf("could not connect: %s", err)
+		}
+		seed(t, db, e, "", queries)
+		return ""
+	}
+
 	mockDB := db.SetupDatabase(t)
 
 	schemaPath := fmt.Sprintf(schemaTemplate, db.Name())
@@ -67,6 +94,17 @@ func Start(t *testing.T, db Database, e engine.Engine, queries []string) string
 		return ""
 	}
 
+	seed(t, db, e, mockDB, queries)
+
+	log.Printf("")
+	log.Printf("---")
+	log.Printf("")
+
+	return mockDB
+}
+
+// seed sends the raw queries which set up the data of a test
+func seed(t *testing.T, db Database, e engine.Engine, mockDB string, queries []string) {
 	for _, q := range queries {
 		var response engine.GQLResponse
 		payload := engine.GQLRequest{
@@ -82,15 +120,19 @@ func Start(t *testing.T, db Database, e engine.Engine, queries []string) string
 			t.Fatalf("mock query has errors %+v", response)
 		}
 	}
-
-	log.Printf("")
-	log.Printf("---")
-	log.Printf("")
-
-	return mockDB
 }
 
 func End(t *testing.T, db Database, e engine.Engine, mockDBName string) {
+	if r, ok := e.(*replay.Replayer); ok {
+		if err := r.Disconnect(); err != nil {
+			t.Fatalf("could not disconnect: %s", err)
+		}
+		if unused := r.Unused(); len(unused) > 0 {
+			t.Errorf("%d recorded requests were not sent; record the golden file again with %s=%s", len(unused), replay.Env, replay.ModeRecord)
+		}
+		return
+	}
+
 	defer teardown(t, db, mockDBName)
 
 	if err := e.Disconnect(); err != nil {